@@ -0,0 +1,104 @@
+package safer
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultBatchConcurrency = 4
+
+// BatchOptions configures a batch lookup
+type BatchOptions struct {
+	// Concurrency is the maximum number of lookups in flight at once. Defaults to 4.
+	Concurrency int
+	// OnResult, when set, is called with every result as soon as it is ready so large batches can be consumed
+	// incrementally. Calls are made one at a time, in completion order.
+	OnResult func(BatchResult)
+}
+
+// BatchResult is the outcome of a single lookup in a batch
+type BatchResult struct {
+	// Index of the query in the input slice
+	Index int
+	// Query as given in the input slice
+	Query string
+	// Snapshot found for the query, nil if Err is set
+	Snapshot *CompanySnapshot
+	// Err from the lookup (e.g. ErrCompanyNotFound). Errors don't stop the rest of the batch.
+	Err error
+}
+
+// GetCompaniesByDOTNumbers - Get company snapshots for many DOT numbers with bounded concurrency. Results are
+// returned in input order, one per input. Duplicate inputs are only looked up once and share the same snapshot.
+func (c *Client) GetCompaniesByDOTNumbers(ctx context.Context, dotNumbers []string, opts BatchOptions) []BatchResult {
+	return c.scraper.scrapeCompanySnapshots(ctx, paramUSDOT, dotNumbers, opts)
+}
+
+// GetCompaniesByMCMXs - Get company snapshots for many MC/MX numbers with bounded concurrency. Results are
+// returned in input order, one per input. Duplicate inputs are only looked up once and share the same snapshot.
+// An "MC" or "MX" prefix is stripped as in GetCompanyByMCMX.
+func (c *Client) GetCompaniesByMCMXs(ctx context.Context, mcmxs []string, opts BatchOptions) []BatchResult {
+	return c.scraper.scrapeCompanySnapshots(ctx, paramMCMX, mcmxs, opts)
+}
+
+func (s *scraper) scrapeCompanySnapshots(ctx context.Context, queryParam string, queries []string, opts BatchOptions) []BatchResult {
+	results := make([]BatchResult, len(queries))
+	// group input positions by normalized query so duplicates are fetched once
+	var unique []Identifier
+	positions := make(map[Identifier][]int)
+	for i, query := range queries {
+		results[i] = BatchResult{Index: i, Query: query}
+		key, err := normalizeIdentifier(queryParam, query)
+		if err != nil {
+			// invalid identifiers fail without a request
+			results[i].Err = err
+			if opts.OnResult != nil {
+				opts.OnResult(results[i])
+			}
+			continue
+		}
+		if _, ok := positions[key]; !ok {
+			unique = append(unique, key)
+		}
+		positions[key] = append(positions[key], i)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > len(unique) {
+		concurrency = len(unique)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan Identifier)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				var snapshot *CompanySnapshot
+				err := ctx.Err()
+				if err == nil {
+					snapshot, err = s.lookupIdentifier(ctx, id)
+				}
+				mu.Lock()
+				for _, i := range positions[id] {
+					results[i].Snapshot, results[i].Err = snapshot, err
+					if opts.OnResult != nil {
+						opts.OnResult(results[i])
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, id := range unique {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,109 @@
+package safer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_GetCompaniesByDOTNumbers(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	requested := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query_string")
+		mu.Lock()
+		requested[query]++
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html")
+		switch query {
+		case "404":
+			w.Write(readTestData("./testdata/not-found.html"))
+		case "400":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.Write(readTestData("./testdata/snapshot-basic.html"))
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	queries := []string{"264184", "404", "1", "264184", "400", "2", "3", "4"}
+	var streamed int
+	results := c.GetCompaniesByDOTNumbers(context.Background(), queries, BatchOptions{
+		Concurrency: 2,
+		OnResult: func(BatchResult) {
+			streamed++
+		},
+	})
+
+	if len(results) != len(queries) {
+		t.Fatalf("results length = %v, want %v", len(results), len(queries))
+	}
+	for i, res := range results {
+		if res.Index != i || res.Query != queries[i] {
+			t.Errorf("results[%d] = {Index: %v, Query: %v}, want {Index: %v, Query: %v}", i, res.Index, res.Query, i, queries[i])
+		}
+		switch res.Query {
+		case "404":
+			if res.Err != ErrCompanyNotFound || res.Snapshot != nil {
+				t.Errorf("results[%d] should be ErrCompanyNotFound but got %v", i, res.Err)
+			}
+		case "400":
+			if res.Err == nil || res.Snapshot != nil {
+				t.Errorf("results[%d] should return an error", i)
+			}
+		default:
+			if res.Err != nil || res.Snapshot == nil {
+				t.Errorf("results[%d] should return a snapshot but got error %v", i, res.Err)
+			}
+		}
+	}
+	if results[0].Snapshot != results[3].Snapshot {
+		t.Errorf("duplicate queries should share a snapshot")
+	}
+	if requested["264184"] != 1 {
+		t.Errorf("duplicate query requested %v times, want 1", requested["264184"])
+	}
+	if maxInFlight > 2 {
+		t.Errorf("max requests in flight = %v, want at most 2", maxInFlight)
+	}
+	if streamed != len(queries) {
+		t.Errorf("OnResult called %v times, want %v", streamed, len(queries))
+	}
+}
+
+func TestClient_GetCompaniesByMCMXs_Canceled(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := c.GetCompaniesByMCMXs(ctx, []string{"1", "2", "3"}, BatchOptions{})
+	for i, res := range results {
+		if !errors.Is(res.Err, context.Canceled) {
+			t.Errorf("results[%d] should return context.Canceled but got %v", i, res.Err)
+		}
+	}
+}
+
+func TestClient_GetCompaniesByDOTNumbers_Empty(t *testing.T) {
+	results := NewClient().GetCompaniesByDOTNumbers(context.Background(), nil, BatchOptions{})
+	if len(results) != 0 {
+		t.Errorf("results length = %v, want 0", len(results))
+	}
+}
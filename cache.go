@@ -0,0 +1,170 @@
+package safer
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache stores raw company snapshot pages so repeated lookups of the same carrier don't hit SAFER.
+// Implementations must be safe for concurrent use. A ttl <= 0 means the entry does not expire.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+// CacheStats counts the cache lookups made by a Client
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+// WithCache sets the cache consulted before every company snapshot lookup. Successful responses are stored
+// for ttl. Keys are the query param and normalized query string, e.g. "USDOT:264184".
+func WithCache(cache Cache, ttl time.Duration) Option {
+	return func(o *options) {
+		o.cache = cache
+		o.cacheTTL = ttl
+	}
+}
+
+// BypassCache returns a context that makes lookups skip the cache read and always fetch a fresh page from
+// SAFER. The fresh page is still written back to the cache.
+func BypassCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+// CacheStats returns the number of cache hits and misses since the client was created
+func (c *Client) CacheStats() CacheStats {
+	if c.scraper.cacheStats == nil {
+		return CacheStats{}
+	}
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.scraper.cacheStats.hits),
+		Misses: atomic.LoadUint64(&c.scraper.cacheStats.misses),
+	}
+}
+
+type bypassCacheKey struct{}
+
+type cacheStats struct {
+	hits   uint64
+	misses uint64
+}
+
+func cacheKey(queryParam, queryString string) string {
+	return queryParam + ":" + strings.ToUpper(strings.TrimSpace(queryString))
+}
+
+func (s *scraper) cacheGet(ctx context.Context, key string) ([]byte, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+	if bypass, _ := ctx.Value(bypassCacheKey{}).(bool); bypass {
+		return nil, false
+	}
+	value, ok := s.cache.Get(key)
+	if s.cacheStats != nil {
+		if ok {
+			atomic.AddUint64(&s.cacheStats.hits, 1)
+		} else {
+			atomic.AddUint64(&s.cacheStats.misses, 1)
+		}
+	}
+	return value, ok
+}
+
+func (s *scraper) cacheSet(key string, value []byte) {
+	if s.cache != nil {
+		s.cache.Set(key, value, s.cacheTTL)
+	}
+}
+
+// LRUCache is an in-memory Cache holding at most a fixed number of entries. When full, the least recently
+// used entry is evicted.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	entries    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache builds an LRUCache holding up to maxEntries entries
+func NewLRUCache(maxEntries int) *LRUCache {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &LRUCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored for key if it exists and has not expired
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.removeElement(elem)
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set stores value for key, evicting the least recently used entry if the cache is full
+func (c *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		c.ll.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Delete removes key from the cache
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired entries not yet evicted
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *LRUCache) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,74 @@
+package safer
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileCache is a Cache storing one file per entry in a directory, so cached pages survive restarts and can be
+// shared between processes. Errors reading or writing files are treated as cache misses.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache builds a FileCache in dir, creating the directory if needed
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+// Get returns the value stored for key if it exists and has not expired
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil || len(data) < 8 {
+		return nil, false
+	}
+	// files start with the expiry as unix nanoseconds, 0 if the entry never expires
+	if expires := int64(binary.BigEndian.Uint64(data[:8])); expires != 0 && time.Now().UnixNano() > expires {
+		c.Delete(key)
+		return nil, false
+	}
+	return data[8:], true
+}
+
+// Set stores value for key. The file is written to a temporary name first so readers never see partial entries.
+func (c *FileCache) Set(key string, value []byte, ttl time.Duration) {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+	data := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(data[:8], uint64(expires))
+	copy(data[8:], value)
+
+	tmp, err := os.CreateTemp(c.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete removes key from the cache
+func (c *FileCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
+
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".html")
+}
@@ -0,0 +1,122 @@
+package safer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testCache(t *testing.T, cache Cache) {
+	t.Helper()
+	if _, ok := cache.Get("USDOT:1"); ok {
+		t.Errorf("Get on an empty cache should miss")
+	}
+	cache.Set("USDOT:1", []byte("one"), 0)
+	if got, ok := cache.Get("USDOT:1"); !ok || string(got) != "one" {
+		t.Errorf("Get() = %q, %v, want %q, true", got, ok, "one")
+	}
+	cache.Set("USDOT:1", []byte("uno"), time.Minute)
+	if got, ok := cache.Get("USDOT:1"); !ok || string(got) != "uno" {
+		t.Errorf("Get() after overwrite = %q, %v, want %q, true", got, ok, "uno")
+	}
+	cache.Delete("USDOT:1")
+	if _, ok := cache.Get("USDOT:1"); ok {
+		t.Errorf("Get after Delete should miss")
+	}
+	cache.Set("USDOT:2", []byte("two"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.Get("USDOT:2"); ok {
+		t.Errorf("Get of an expired entry should miss")
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	testCache(t, NewLRUCache(10))
+}
+
+func TestLRUCache_Eviction(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", []byte("a"), 0)
+	c.Set("b", []byte("b"), 0)
+	c.Get("a") // a is now the most recently used
+	c.Set("c", []byte("c"), 0)
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("least recently used entry should have been evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("recently used entry should not have been evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %v, want %v", c.Len(), 2)
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	c, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCache should return no error, but got %v", err)
+	}
+	testCache(t, c)
+}
+
+func TestClient_Cache(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(readTestData("./testdata/snapshot-basic.html"))
+	}))
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	c := NewClient(WithBaseURL(ts.URL), WithCache(cache, time.Minute))
+	first, err := c.GetCompanyByDOTNumber("264184")
+	if err != nil {
+		t.Fatalf("GetCompanyByDOTNumber should return no error, but got %v", err)
+	}
+	second, err := c.GetCompanyByDOTNumber(" 264184 ")
+	if err != nil {
+		t.Fatalf("GetCompanyByDOTNumber should return no error, but got %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("cached snapshot = %v, want %v", second, first)
+	}
+	if calls != 1 {
+		t.Errorf("server calls = %v, want %v", calls, 1)
+	}
+	if _, ok := cache.Get("USDOT:264184"); !ok {
+		t.Errorf("snapshot should be cached under the normalized key")
+	}
+	if stats := c.CacheStats(); stats != (CacheStats{Hits: 1, Misses: 1}) {
+		t.Errorf("CacheStats() = %+v, want 1 hit and 1 miss", stats)
+	}
+
+	if _, err := c.GetCompanyByDOTNumberContext(BypassCache(context.Background()), "264184"); err != nil {
+		t.Fatalf("GetCompanyByDOTNumberContext should return no error, but got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("server calls after bypass = %v, want %v", calls, 2)
+	}
+	if stats := c.CacheStats(); stats != (CacheStats{Hits: 1, Misses: 1}) {
+		t.Errorf("CacheStats() after bypass = %+v, want 1 hit and 1 miss", stats)
+	}
+}
+
+func TestClient_CacheSkipsNotFound(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	c := NewClient(WithBaseURL(ts.URL), WithCache(cache, time.Minute))
+	c.companySnapshotURL = ts.URL + "/snapshot-not-found"
+	if _, err := c.GetCompanyByDOTNumber("1"); err != ErrCompanyNotFound {
+		t.Errorf("GetCompanyByDOTNumber should return ErrCompanyNotFound but got %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("not found pages should not be cached")
+	}
+}
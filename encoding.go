@@ -0,0 +1,66 @@
+package safer
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// acceptEncoding lists the content encodings decodeBody can decode. Setting Accept-Encoding by hand turns off the
+// transport's transparent gzip decoding, so every encoding advertised here must be decoded by the client itself.
+const acceptEncoding = "gzip, deflate"
+
+// decodeBody wraps a response body to undo its Content-Encoding. Encodings are undone in the reverse of the order
+// they were applied. Returns an error matching ErrUnsupportedEncoding for an encoding it can't decode.
+func decodeBody(body io.ReadCloser, contentEncoding string) (io.ReadCloser, error) {
+	if strings.TrimSpace(contentEncoding) == "" {
+		return body, nil
+	}
+	encodings := strings.Split(contentEncoding, ",")
+	var r io.Reader = body
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch encoding := strings.ToLower(strings.TrimSpace(encodings[i])); encoding {
+		case "identity":
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(r)
+		case "deflate":
+			r, err = newDeflateReader(r)
+		default:
+			err = fmt.Errorf("%w %q", ErrUnsupportedEncoding, encoding)
+		}
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+	}
+	return decodedBody{Reader: r, body: body}, nil
+}
+
+// newDeflateReader reads a deflate body. The standard calls for zlib framing, but some servers send a raw deflate
+// stream, so the zlib header is checked for first.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// decodedBody reads the decoded response and closes the underlying body
+type decodedBody struct {
+	io.Reader
+	body io.Closer
+}
+
+func (b decodedBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,123 @@
+package safer
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	default:
+		return data
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newCompressingServer serves page with the given Content-Encoding, counting requests
+func newCompressingServer(t *testing.T, contentEncoding, encoding string, page []byte, requests *int32) *httptest.Server {
+	body := compress(t, encoding, page)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if got := r.Header.Get("Accept-Encoding"); got != "gzip, deflate" {
+			t.Errorf("Accept-Encoding = %q, want %q", got, "gzip, deflate")
+		}
+		w.Header().Set("Content-Type", "text/html")
+		if contentEncoding != "" {
+			w.Header().Set("Content-Encoding", contentEncoding)
+		}
+		w.Write(body)
+	}))
+}
+
+func TestClient_CompressedResponses(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentEncoding string
+		encoding        string
+	}{
+		{"identity", "", ""},
+		{"gzip", "gzip", "gzip"},
+		{"x-gzip", "x-gzip", "gzip"},
+		{"deflate", "deflate", "deflate"},
+		{"raw deflate", "deflate", "raw deflate"},
+		{"uppercase", "GZIP", "gzip"},
+	}
+	want, err := ParseCompanySnapshot(bytes.NewReader(readTestData("./testdata/snapshot-basic.html")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		var requests int32
+		ts := newCompressingServer(t, tt.contentEncoding, tt.encoding, readTestData("./testdata/snapshot-basic.html"), &requests)
+		c := NewClient(WithBaseURL(ts.URL))
+		snapshot, err := c.GetCompanyByDOTNumber("264184")
+		if err != nil {
+			t.Errorf("%s: GetCompanyByDOTNumber should return no error, but got %v", tt.name, err)
+		} else if snapshot.LegalName != want.LegalName || snapshot.USCrashes != want.USCrashes {
+			t.Errorf("%s: GetCompanyByDOTNumber() = %v, want %v", tt.name, snapshot, want)
+		}
+		ts.Close()
+	}
+}
+
+func TestClient_CompressedSearch(t *testing.T) {
+	var requests int32
+	ts := newCompressingServer(t, "gzip", "gzip", readTestData("./testdata/search-result-short.html"), &requests)
+	defer ts.Close()
+
+	results, err := NewClient(WithBaseURL(ts.URL)).SearchCompaniesByName("schneider")
+	if err != nil {
+		t.Fatalf("SearchCompaniesByName should return no error, but got %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("SearchCompaniesByName() returned %d results, want 4", len(results))
+	}
+}
+
+func TestClient_UnsupportedEncoding(t *testing.T) {
+	var requests int32
+	ts := newCompressingServer(t, "br", "", readTestData("./testdata/snapshot-basic.html"), &requests)
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	if _, err := c.GetCompanyByDOTNumber("264184"); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("GetCompanyByDOTNumber() error = %v, want ErrUnsupportedEncoding", err)
+	}
+	if err := c.SearchCompaniesByNameFunc(context.Background(), "schneider", func(CompanyResult) bool { return true }); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("SearchCompaniesByNameFunc() error = %v, want ErrUnsupportedEncoding", err)
+	}
+	if requests != 2 {
+		t.Errorf("unsupported encodings sent %d requests, want 2 without retries", requests)
+	}
+}
+
+func TestDecodeBody_Corrupt(t *testing.T) {
+	body, err := decodeBody(io.NopCloser(bytes.NewReader([]byte("not gzip"))), "gzip")
+	if err == nil {
+		body.Close()
+		t.Error("decodeBody should return an error for a corrupt gzip body")
+	}
+}
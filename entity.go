@@ -1,6 +1,9 @@
 package safer
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // CompanyResult is the search result returned from a company query by name
 type CompanyResult struct {
@@ -9,38 +12,71 @@ type CompanyResult struct {
 	Location  string `json:"location"`
 }
 
-// CompanySnapshot data parsed from the https://safer.fmcsa.dot.gov/CompanySnapshot.aspx website
+// CompanySnapshot data parsed from the https://safer.fmcsa.dot.gov/CompanySnapshot.aspx website.
+//
+// Vocabulary fields hold stable codes for the known SAFER values. Page text that isn't a known value is kept in
+// the matching ...Other field, and the operating status is also kept as shown on the page in OperatingStatusRaw.
 type CompanySnapshot struct {
-	USVehicleInspections     InspectionSummary `json:"us_vehicle_inspections"`
-	USDriverInspections      InspectionSummary `json:"us_driver_inspections"`
-	USHazmatInspections      InspectionSummary `json:"us_hazmat_inspections"`
-	USIEPInspections         InspectionSummary `json:"us_iep_inspections"`
-	CanadaVehicleInspections InspectionSummary `json:"canada_vehicle_inspections"`
-	CanadaDriverInspections  InspectionSummary `json:"canada_driver_inspections"`
-	USCrashes                CrashSummary      `json:"us_crashes"`
-	CanadaCrashes            CrashSummary      `json:"canada_crashes"`
-	Safety                   SafetyRating      `json:"safety"`
-	LatestUpdateDate         *time.Time        `json:"latest_update_date"`
-	OutOfServiceDate         *time.Time        `json:"out_of_service_date"`
-	MCS150FormDate           *time.Time        `json:"mcs_150_form_date"`
-	OperationClassification  []string          `json:"operation_classification"`
-	CarrierOperation         []string          `json:"carrier_operation"`
-	CargoCarried             []string          `json:"cargo_carried"`
-	LegalName                string            `json:"legal_name"`
-	DBAName                  string            `json:"dba_name"`
-	EntityType               string            `json:"entity_type"`
-	PhysicalAddress          string            `json:"physical_address"`
-	Phone                    string            `json:"phone"`
-	MailingAddress           string            `json:"mailing_address"`
-	DOTNumber                string            `json:"dot_number"`
-	StateCarrierID           string            `json:"state_carrier_id"`
-	MCMXFFNumbers            []string          `json:"mc_mx_ff_numbers"`
-	DUNSNumber               string            `json:"duns_number"`
-	MCS150Mileage            int               `json:"mcs_150_mileage"`
-	MCS150Year               string            `json:"mcs_150_year"`
-	OperatingStatus          string            `json:"operating_status"`
-	PowerUnits               int               `json:"power_units"`
-	Drivers                  int               `json:"drivers"`
+	USVehicleInspections         InspectionSummary         `json:"us_vehicle_inspections"`
+	USDriverInspections          InspectionSummary         `json:"us_driver_inspections"`
+	USHazmatInspections          InspectionSummary         `json:"us_hazmat_inspections"`
+	USIEPInspections             InspectionSummary         `json:"us_iep_inspections"`
+	CanadaVehicleInspections     InspectionSummary         `json:"canada_vehicle_inspections"`
+	CanadaDriverInspections      InspectionSummary         `json:"canada_driver_inspections"`
+	USCrashes                    CrashSummary              `json:"us_crashes"`
+	CanadaCrashes                CrashSummary              `json:"canada_crashes"`
+	Safety                       SafetyRating              `json:"safety"`
+	LatestUpdateDate             *time.Time                `json:"latest_update_date"`
+	OutOfServiceDate             *time.Time                `json:"out_of_service_date"`
+	MCS150FormDate               *time.Time                `json:"mcs_150_form_date"`
+	OperationClassification      []OperationClassification `json:"operation_classification"`
+	OperationClassificationOther []string                  `json:"operation_classification_other"`
+	CarrierOperation             []CarrierOperation        `json:"carrier_operation"`
+	CarrierOperationOther        []string                  `json:"carrier_operation_other"`
+	CargoCarried                 []Cargo                   `json:"cargo_carried"`
+	CargoCarriedOther            []string                  `json:"cargo_carried_other"`
+	LegalName                    string                    `json:"legal_name"`
+	DBAName                      string                    `json:"dba_name"`
+	EntityType                   []EntityType              `json:"entity_type"`
+	EntityTypeOther              []string                  `json:"entity_type_other"`
+	PhysicalAddress              Address                   `json:"physical_address"`
+	Phone                        string                    `json:"phone"`
+	MailingAddress               Address                   `json:"mailing_address"`
+	DOTNumber                    string                    `json:"dot_number"`
+	StateCarrierID               string                    `json:"state_carrier_id"`
+	MCMXFFNumbers                []string                  `json:"mc_mx_ff_numbers"`
+	DocketNumbers                []Identifier              `json:"docket_numbers"`
+	DUNSNumber                   string                    `json:"duns_number"`
+	MCS150Mileage                int                       `json:"mcs_150_mileage"`
+	MCS150Year                   string                    `json:"mcs_150_year"`
+	OperatingStatus              OperatingStatus           `json:"operating_status"`
+	OperatingStatusRaw           string                    `json:"operating_status_raw"`
+	PowerUnits                   int                       `json:"power_units"`
+	Drivers                      int                       `json:"drivers"`
+}
+
+// Address parsed from the multiline physical or mailing address. Raw holds the original lines so nothing is lost
+// when a line can't be split into its parts.
+type Address struct {
+	Street  string   `json:"street"`
+	City    string   `json:"city"`
+	State   string   `json:"state"`
+	ZIP     string   `json:"zip"`
+	ZIP4    string   `json:"zip4"`
+	Country string   `json:"country"`
+	Raw     []string `json:"raw"`
+}
+
+// String returns the address on a single line, e.g. "3101 S PACKERLAND DR GREEN BAY, WI 54313"
+func (a Address) String() string {
+	var b strings.Builder
+	for i, line := range a.Raw {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(strings.ReplaceAll(line, "\u00a0 ", "")) // remove &nbsp;
+	}
+	return b.String()
 }
 
 // InspectionSummary for 24 months prior to LatestUpdateDate.
@@ -65,6 +101,7 @@ type CrashSummary struct {
 type SafetyRating struct {
 	RatingDate *time.Time `json:"rating_date"`
 	ReviewDate *time.Time `json:"review_date"`
-	Rating     string     `json:"rating"`
+	Rating     Rating     `json:"rating"`
+	RatingRaw  string     `json:"rating_raw"`
 	Type       string     `json:"type"`
 }
@@ -1,8 +1,100 @@
 package safer
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
 
 var (
 	// ErrCompanyNotFound is thrown when a company is not found for the searched MC/MX/DOT number
 	ErrCompanyNotFound = errors.New("company not found")
+	// ErrCompanyInactive is thrown when the searched MC/MX/DOT number belongs to a known company whose record has
+	// been deactivated
+	ErrCompanyInactive = errors.New("company record inactive")
+	// ErrLayoutChanged is matched by the *LayoutError returned by strict parsing when the SAFER page no longer has
+	// the expected structure
+	ErrLayoutChanged = errors.New("SAFER page layout changed")
+	// ErrInvalidIdentifier is matched by the *InvalidIdentifierError returned when a DOT or MC/MX number isn't
+	// valid, before any request is sent
+	ErrInvalidIdentifier = errors.New("invalid identifier")
+	// ErrUnsupportedEncoding is returned when SAFER responds with a Content-Encoding the client can't decode
+	ErrUnsupportedEncoding = errors.New("unsupported Content-Encoding")
+	// ErrRateLimited is matched by the *HTTPError for a 429 Too Many Requests response
+	ErrRateLimited = errors.New("rate limited by SAFER")
+	// ErrUpstreamUnavailable is matched by the *HTTPError for a 500, 502, 503 or 504 response, and by transport
+	// errors such as a refused or reset connection
+	ErrUpstreamUnavailable = errors.New("SAFER unavailable")
+	// ErrBlocked is matched by the *HTTPError for a 403 Forbidden response, which SAFER sends to clients it has
+	// blocked, and by the error for a request rejected or captcha page sent in place of the requested page
+	ErrBlocked = errors.New("blocked by SAFER")
+	// ErrMaintenance is returned when SAFER sends a maintenance notice in place of the requested page
+	ErrMaintenance = errors.New("SAFER down for maintenance")
+	// ErrParse is matched when a page can't be parsed, including the *LayoutError returned by strict parsing
+	ErrParse = errors.New("SAFER page could not be parsed")
 )
+
+// maxErrorBodySnippet is the most of a failed response's body kept in an HTTPError
+const maxErrorBodySnippet = 512
+
+// HTTPError is returned when SAFER responds with a status other than 200. It matches ErrRateLimited,
+// ErrUpstreamUnavailable or ErrBlocked with errors.Is depending on the status code.
+type HTTPError struct {
+	// StatusCode of the response, e.g. 503
+	StatusCode int
+	// Status line of the response, e.g. "503 Service Unavailable"
+	Status string
+	// URL requested
+	URL string
+	// Body holds the start of the response body, to help tell an outage page from a block page
+	Body string
+	// RetryAfter is the delay requested by the Retry-After header, zero if there wasn't one
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	return e.Status + " Response from SAFER"
+}
+
+// Is matches the sentinel error for the response's status code
+func (e *HTTPError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	case http.StatusForbidden:
+		return target == ErrBlocked
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return target == ErrUpstreamUnavailable
+	}
+	return false
+}
+
+// kindError gives an underlying error one of the sentinel errors to match with errors.Is, while errors.As and
+// errors.Unwrap still reach the underlying error
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return fmt.Sprintf("%v: %v", e.kind, e.err)
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+// unavailableError marks a transport error as ErrUpstreamUnavailable
+func unavailableError(err error) error {
+	return &kindError{kind: ErrUpstreamUnavailable, err: err}
+}
+
+// parseError marks an error parsing a page as ErrParse
+func parseError(err error) error {
+	return &kindError{kind: ErrParse, err: err}
+}
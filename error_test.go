@@ -0,0 +1,89 @@
+package safer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPError_Is(t *testing.T) {
+	sentinels := []error{ErrRateLimited, ErrUpstreamUnavailable, ErrBlocked, ErrParse}
+	tests := []struct {
+		statusCode int
+		want       error
+	}{
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusForbidden, ErrBlocked},
+		{http.StatusInternalServerError, ErrUpstreamUnavailable},
+		{http.StatusBadGateway, ErrUpstreamUnavailable},
+		{http.StatusServiceUnavailable, ErrUpstreamUnavailable},
+		{http.StatusGatewayTimeout, ErrUpstreamUnavailable},
+		{http.StatusNotFound, nil},
+		{http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		err := error(&HTTPError{StatusCode: tt.statusCode})
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("errors.Is(HTTPError %d, %v) = %v, want %v", tt.statusCode, sentinel, got, !got)
+			}
+		}
+	}
+}
+
+func TestClient_HTTPError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("<html><body>Too many requests. " + strings.Repeat("Please slow down. ", 100) + "</body></html>"))
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL), WithRetryPolicy(RetryPolicy{}))
+	_, err := c.GetCompanyByDOTNumber("264184")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("GetCompanyByDOTNumber() error = %v, want ErrRateLimited", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("GetCompanyByDOTNumber() error = %v, want an *HTTPError", err)
+	}
+	if httpErr.StatusCode != http.StatusTooManyRequests || httpErr.RetryAfter != 30*time.Second {
+		t.Errorf("HTTPError = %+v, want status 429 and RetryAfter 30s", httpErr)
+	}
+	if !strings.HasPrefix(httpErr.URL, ts.URL+companySnapshotPath+"?") || !strings.Contains(httpErr.URL, "query_string=264184") {
+		t.Errorf("HTTPError.URL = %v, want the snapshot request", httpErr.URL)
+	}
+	if !strings.HasPrefix(httpErr.Body, "<html><body>Too many requests.") || len(httpErr.Body) != maxErrorBodySnippet {
+		t.Errorf("HTTPError.Body = %q, want the first %d bytes of the page", httpErr.Body, maxErrorBodySnippet)
+	}
+}
+
+func TestClient_TransportError(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL), WithRetryPolicy(RetryPolicy{}))
+	_, err := c.GetCompanyByDOTNumber("264184")
+	if !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("GetCompanyByDOTNumber() error = %v, want ErrUpstreamUnavailable", err)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Errorf("GetCompanyByDOTNumber() error = %v, want it to wrap the *url.Error", err)
+	}
+	if _, err := c.SearchCompaniesByName("schneider"); !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("SearchCompaniesByName() error = %v, want ErrUpstreamUnavailable", err)
+	}
+}
+
+func TestLayoutError_IsParse(t *testing.T) {
+	err := error(&LayoutError{MissingSections: []string{SectionGeneralInfo}})
+	if !errors.Is(err, ErrParse) || !errors.Is(err, ErrLayoutChanged) {
+		t.Errorf("LayoutError should match ErrParse and ErrLayoutChanged")
+	}
+}
@@ -3,6 +3,7 @@ package safer_test
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/brandenc40/safer"
 )
@@ -36,3 +37,18 @@ func ExampleClient_SearchCompaniesByName() {
 	}
 	fmt.Printf("%#v", res[0])
 }
+
+func ExampleParseCompanySnapshot() {
+	f, err := os.Open("testdata/snapshot-basic.html")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer f.Close()
+
+	snapshot, err := safer.ParseCompanySnapshot(f)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Println(snapshot.LegalName, snapshot.DOTNumber)
+	// Output: SCHNEIDER NATIONAL CARRIERS INC 264184
+}
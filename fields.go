@@ -0,0 +1,219 @@
+package safer
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// snapshotField sets a CompanySnapshot field from the output of the parser it requires
+type snapshotField struct {
+	parser string
+	set    func(s *CompanySnapshot, v interface{})
+}
+
+// mileageYear is the output of ParserMileageYear
+type mileageYear struct {
+	mileage int
+	year    string
+}
+
+// snapshotFields are the fields a FieldMapping can set, keyed by their JSON name
+var snapshotFields = newSnapshotFields()
+
+func newSnapshotFields() map[string]snapshotField {
+	fields := map[string]snapshotField{
+		"latest_update_date": {ParserDate, func(s *CompanySnapshot, v interface{}) {
+			s.LatestUpdateDate = v.(*time.Time)
+		}},
+		"entity_type": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			s.EntityType, s.EntityTypeOther = parseEntityTypes(v.(string))
+		}},
+		"operating_status": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			s.OperatingStatusRaw = v.(string)
+			s.OperatingStatus = parseOperatingStatus(s.OperatingStatusRaw)
+		}},
+		"out_of_service_date": {ParserDate, func(s *CompanySnapshot, v interface{}) {
+			s.OutOfServiceDate = v.(*time.Time)
+		}},
+		"legal_name": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			s.LegalName = v.(string)
+		}},
+		"dba_name": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			s.DBAName = v.(string)
+		}},
+		"physical_address": {ParserAddress, func(s *CompanySnapshot, v interface{}) {
+			s.PhysicalAddress = v.(Address)
+		}},
+		"phone": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			s.Phone = v.(string)
+		}},
+		"mailing_address": {ParserAddress, func(s *CompanySnapshot, v interface{}) {
+			s.MailingAddress = v.(Address)
+		}},
+		"dot_number": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			s.DOTNumber = v.(string)
+		}},
+		"state_carrier_id": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			s.StateCarrierID = v.(string)
+		}},
+		"mc_mx_ff_numbers": {ParserTexts, func(s *CompanySnapshot, v interface{}) {
+			s.MCMXFFNumbers = v.([]string)
+			s.DocketNumbers = docketIdentifiers(s.MCMXFFNumbers)
+		}},
+		"duns_number": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			if s.DUNSNumber = v.(string); s.DUNSNumber == "--" {
+				s.DUNSNumber = ""
+			}
+		}},
+		"power_units": {ParserInt, func(s *CompanySnapshot, v interface{}) {
+			s.PowerUnits = v.(int)
+		}},
+		"drivers": {ParserInt, func(s *CompanySnapshot, v interface{}) {
+			s.Drivers = v.(int)
+		}},
+		"mcs_150_form_date": {ParserDate, func(s *CompanySnapshot, v interface{}) {
+			s.MCS150FormDate = v.(*time.Time)
+		}},
+		"mcs_150_mileage": {ParserMileageYear, func(s *CompanySnapshot, v interface{}) {
+			s.MCS150Mileage, s.MCS150Year = v.(mileageYear).mileage, v.(mileageYear).year
+		}},
+		"operation_classification": {ParserCheckboxes, func(s *CompanySnapshot, v interface{}) {
+			for _, classification := range v.([]string) {
+				if known, ok := operationClassifications[vocabularyKey(classification)]; ok {
+					s.OperationClassification = append(s.OperationClassification, known)
+				} else {
+					s.OperationClassificationOther = append(s.OperationClassificationOther, classification)
+				}
+			}
+		}},
+		"carrier_operation": {ParserCheckboxes, func(s *CompanySnapshot, v interface{}) {
+			for _, op := range v.([]string) {
+				if known, ok := carrierOperations[vocabularyKey(op)]; ok {
+					s.CarrierOperation = append(s.CarrierOperation, known)
+				} else {
+					s.CarrierOperationOther = append(s.CarrierOperationOther, op)
+				}
+			}
+		}},
+		"cargo_carried": {ParserCheckboxes, func(s *CompanySnapshot, v interface{}) {
+			for _, cargo := range v.([]string) {
+				if known, ok := cargoTypes[vocabularyKey(cargo)]; ok {
+					s.CargoCarried = append(s.CargoCarried, known)
+				} else {
+					s.CargoCarriedOther = append(s.CargoCarriedOther, cargo)
+				}
+			}
+		}},
+		"safety.rating_date": {ParserDate, func(s *CompanySnapshot, v interface{}) {
+			s.Safety.RatingDate = v.(*time.Time)
+		}},
+		"safety.review_date": {ParserDate, func(s *CompanySnapshot, v interface{}) {
+			s.Safety.ReviewDate = v.(*time.Time)
+		}},
+		"safety.rating": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			s.Safety.RatingRaw = v.(string)
+			s.Safety.Rating = parseRating(s.Safety.RatingRaw)
+		}},
+		"safety.type": {ParserText, func(s *CompanySnapshot, v interface{}) {
+			s.Safety.Type = v.(string)
+		}},
+	}
+	inspections := map[string]func(s *CompanySnapshot) *InspectionSummary{
+		"us_vehicle_inspections":     func(s *CompanySnapshot) *InspectionSummary { return &s.USVehicleInspections },
+		"us_driver_inspections":      func(s *CompanySnapshot) *InspectionSummary { return &s.USDriverInspections },
+		"us_hazmat_inspections":      func(s *CompanySnapshot) *InspectionSummary { return &s.USHazmatInspections },
+		"us_iep_inspections":         func(s *CompanySnapshot) *InspectionSummary { return &s.USIEPInspections },
+		"canada_vehicle_inspections": func(s *CompanySnapshot) *InspectionSummary { return &s.CanadaVehicleInspections },
+		"canada_driver_inspections":  func(s *CompanySnapshot) *InspectionSummary { return &s.CanadaDriverInspections },
+	}
+	for prefix, summary := range inspections {
+		summary := summary
+		fields[prefix+".inspections"] = snapshotField{ParserInt, func(s *CompanySnapshot, v interface{}) {
+			summary(s).Inspections = v.(int)
+		}}
+		fields[prefix+".out_of_service"] = snapshotField{ParserInt, func(s *CompanySnapshot, v interface{}) {
+			summary(s).OutOfService = v.(int)
+		}}
+		fields[prefix+".out_of_service_pct"] = snapshotField{ParserPercent, func(s *CompanySnapshot, v interface{}) {
+			summary(s).OutOfServicePct = v.(float32)
+		}}
+		fields[prefix+".national_average"] = snapshotField{ParserPercent, func(s *CompanySnapshot, v interface{}) {
+			summary(s).NationalAverage = v.(float32)
+		}}
+	}
+	crashes := map[string]func(s *CompanySnapshot) *CrashSummary{
+		"us_crashes":     func(s *CompanySnapshot) *CrashSummary { return &s.USCrashes },
+		"canada_crashes": func(s *CompanySnapshot) *CrashSummary { return &s.CanadaCrashes },
+	}
+	for prefix, summary := range crashes {
+		summary := summary
+		fields[prefix+".fatal"] = snapshotField{ParserInt, func(s *CompanySnapshot, v interface{}) {
+			summary(s).Fatal = v.(int)
+		}}
+		fields[prefix+".injury"] = snapshotField{ParserInt, func(s *CompanySnapshot, v interface{}) {
+			summary(s).Injury = v.(int)
+		}}
+		fields[prefix+".tow"] = snapshotField{ParserInt, func(s *CompanySnapshot, v interface{}) {
+			summary(s).Tow = v.(int)
+		}}
+		fields[prefix+".total"] = snapshotField{ParserInt, func(s *CompanySnapshot, v interface{}) {
+			summary(s).Total = v.(int)
+		}}
+	}
+	return fields
+}
+
+// fieldParser parses the nodes selected for a field, recording the field's status in the report
+type fieldParser func(p *snapshotParser, name string, nodes []*html.Node) interface{}
+
+// fieldParsers are the parsers a FieldMapping can name
+var fieldParsers = map[string]fieldParser{
+	ParserText: func(p *snapshotParser, name string, nodes []*html.Node) interface{} {
+		return p.text(name, firstText(nodes))
+	},
+	ParserTexts: func(p *snapshotParser, name string, nodes []*html.Node) interface{} {
+		return p.texts(name, nodeTexts(nodes))
+	},
+	ParserInt: func(p *snapshotParser, name string, nodes []*html.Node) interface{} {
+		return p.int(name, firstText(nodes))
+	},
+	ParserPercent: func(p *snapshotParser, name string, nodes []*html.Node) interface{} {
+		return p.pct(name, firstText(nodes))
+	},
+	ParserDate: func(p *snapshotParser, name string, nodes []*html.Node) interface{} {
+		return p.date(name, firstText(nodes))
+	},
+	ParserAddress: func(p *snapshotParser, name string, nodes []*html.Node) interface{} {
+		return p.address(name, nodeTexts(nodes))
+	},
+	ParserMileageYear: func(p *snapshotParser, name string, nodes []*html.Node) interface{} {
+		mileage, year := p.mileageYear(name, firstText(nodes))
+		return mileageYear{mileage: mileage, year: year}
+	},
+	ParserCheckboxes: func(p *snapshotParser, name string, nodes []*html.Node) interface{} {
+		labels := checkedLabels(nodes)
+		p.set(name, checkedStatus(len(labels)))
+		return labels
+	},
+}
+
+// firstText returns the first non-blank text of nodes
+func firstText(nodes []*html.Node) string {
+	for _, node := range nodes {
+		if text := strings.TrimSpace(node.Data); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// nodeTexts returns the trimmed text of every node
+func nodeTexts(nodes []*html.Node) []string {
+	out := make([]string, len(nodes))
+	for i, node := range nodes {
+		out[i] = strings.TrimSpace(node.Data)
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package safer
+
+import (
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// checkboxRowsXpath selects the rows of a checkbox grid (operation classification, carrier operation, cargo
+// carried) from the row that follows the grid's label
+const checkboxRowsXpath = "/td/table/tbody/tr[2]/td/table/tbody/tr"
+
+var (
+	checkboxLabelSelector      = mustCompileSelector("/td/font/text()")
+	checkboxExtraLabelSelector = mustCompileSelector("/td[2]/text()")
+	boldValueSelector          = mustCompileSelector("/font/b/text()")
+)
+
+// labelKey normalizes a label for lookup, ignoring case, whitespace and the trailing colon
+func labelKey(label string) string {
+	return vocabularyKey(strings.TrimSuffix(strings.TrimSpace(label), ":"))
+}
+
+// parseLabeledSection finds the fields of a section such as general info by their labels rather than their
+// position, so rows SAFER adds or reorders don't shift the fields after them. Labels in a th are followed by their
+// value in the next td. Labels in a td head a checkbox grid held in the next row. Fields whose label isn't on the
+// page, or has no value after it, are reported as missing with a warning.
+func (p *snapshotParser) parseLabeledSection(snapshot *CompanySnapshot, section *compiledSection, table *html.Node) {
+	found := make([]bool, len(section.fields))
+	for row := table.FirstChild; row != nil; row = row.NextSibling {
+		if row.Type != html.ElementNode || row.Data != "tr" {
+			continue
+		}
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type != html.ElementNode || htmlquery.SelectAttr(cell, "class") != "querylabelbkg" {
+				continue
+			}
+			label := strings.TrimSpace(htmlquery.InnerText(cell))
+			i, ok := section.labels[labelKey(label)]
+			if !ok {
+				p.unknownLabel(label)
+				continue
+			}
+			var value *html.Node
+			if cell.Data == "th" {
+				value = nextElement(cell, "td")
+			} else {
+				value = nextElement(row, "tr")
+			}
+			found[i] = true
+			f := &section.fields[i]
+			if value == nil {
+				p.warn(section.name, "no value for %q", label)
+				p.set(f.name, FieldMissing)
+				continue
+			}
+			var nodes []*html.Node
+			if f.path != nil {
+				nodes = f.path.find(value)
+			} else {
+				nodes = valueTexts(value)
+			}
+			f.set(snapshot, f.parse(p, f.name, nodes))
+		}
+	}
+	for i, f := range section.fields {
+		if !found[i] {
+			p.warn(section.name, "label %q not found", f.label)
+			p.set(f.name, FieldMissing)
+		}
+	}
+}
+
+// valueTexts returns the text of a value cell. Highlighted values such as drivers and an out-of-service status
+// are wrapped in <font><b> instead.
+func valueTexts(cell *html.Node) []*html.Node {
+	for child := cell.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode && strings.TrimSpace(child.Data) != "" {
+			return []*html.Node{child}
+		}
+	}
+	if bold := boldValueSelector.findOne(cell); bold != nil {
+		return []*html.Node{bold}
+	}
+	return nil
+}
+
+// checkedLabels returns the labels of the checked rows of a checkbox grid. A row is checked when one of its
+// queryfield cells holds an X.
+func checkedLabels(rows []*html.Node) []string {
+	var labels []string
+	for _, row := range rows {
+		if !isChecked(row) {
+			continue
+		}
+		label := checkboxLabelSelector.text(row)
+		if label == "" {
+			// optional extra classifications (not all will have this)
+			label = checkboxExtraLabelSelector.text(row)
+		}
+		if label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// isChecked reports whether node has a descendant td of class queryfield with a text of exactly "X"
+func isChecked(node *html.Node) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		if child.Data == "td" && htmlquery.SelectAttr(child, "class") == "queryfield" {
+			for text := child.FirstChild; text != nil; text = text.NextSibling {
+				if text.Type == html.TextNode && text.Data == "X" {
+					return true
+				}
+			}
+		}
+		if isChecked(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextElement returns the next sibling of node that is a tag element, or nil if there isn't one
+func nextElement(node *html.Node, tag string) *html.Node {
+	for next := node.NextSibling; next != nil; next = next.NextSibling {
+		if next.Type == html.ElementNode && next.Data == tag {
+			return next
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package safer
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	legalNameRow = `     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#Carrier">Legal Name:</A></TH>
+       <TD class="queryfield" valign=top colspan=3>SCHNEIDER NATIONAL CARRIERS INC&nbsp;</TD>
+     </TR>
+`
+	newEntrantRow = `     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#Status">New Entrant Status:</A></TH>
+       <TD colspan=3 class="queryfield" valign=top>(Active)</TD>
+     </TR>
+`
+)
+
+func TestParseGeneralInfo_Reordered(t *testing.T) {
+	data := readTestData("./testdata/snapshot-basic.html")
+	want, err := ParseCompanySnapshot(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// move the legal name to the end of the table and insert a row SAFER might add before it
+	page := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.Contains(page, legalNameRow) {
+		t.Fatal("snapshot-basic.html doesn't contain the legal name row")
+	}
+	page = strings.Replace(page, legalNameRow, newEntrantRow, 1)
+	page = strings.Replace(page, "     <TR>\n       <!-- BEGIN: Cargo Carried -->", legalNameRow+"     <TR>\n       <!-- BEGIN: Cargo Carried -->", 1)
+
+	got, report, err := ParseCompanySnapshotWithReport(strings.NewReader(page), ParseOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("ParseCompanySnapshotWithReport should return no error, but got %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCompanySnapshotWithReport() = %v, want %v", got, want)
+	}
+	if wantLabels := []string{"New Entrant Status:"}; !reflect.DeepEqual(report.UnknownLabels, wantLabels) {
+		t.Errorf("UnknownLabels = %v, want %v", report.UnknownLabels, wantLabels)
+	}
+}
+
+func Test_labelKey(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"Legal Name:", "LEGALNAME"},
+		{" legal name ", "LEGALNAME"},
+		{"MCS-150 Mileage (Year):", "MCS-150MILEAGE(YEAR)"},
+	}
+	for _, tt := range tests {
+		if got := labelKey(tt.label); got != tt.want {
+			t.Errorf("labelKey(%q) = %v, want %v", tt.label, got, tt.want)
+		}
+	}
+}
@@ -4,5 +4,6 @@ go 1.16
 
 require (
 	github.com/antchfx/htmlquery v1.2.4
+	github.com/antchfx/xpath v1.2.0
 	golang.org/x/net v0.0.0-20210510120150-4163338589ed
 )
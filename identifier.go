@@ -0,0 +1,209 @@
+package safer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxIdentifierLength bounds the digits of a DOT or docket number, which are at most 8 digits long
+const maxIdentifierLength = 8
+
+// IdentifierType is the kind of number that identifies a carrier
+type IdentifierType string
+
+// Known identifier types
+const (
+	IdentifierUSDOT IdentifierType = "USDOT"
+	// IdentifierMC is a motor carrier docket number
+	IdentifierMC IdentifierType = "MC"
+	// IdentifierMX is a Mexico-domiciled carrier docket number
+	IdentifierMX IdentifierType = "MX"
+	// IdentifierFF is a freight forwarder docket number
+	IdentifierFF IdentifierType = "FF"
+)
+
+// identifierPrefixes are the prefixes ParseIdentifier recognizes, in the order they're tried
+var identifierPrefixes = []struct {
+	prefix string
+	typ    IdentifierType
+}{
+	{"USDOT", IdentifierUSDOT},
+	{"DOT", IdentifierUSDOT},
+	{"MC", IdentifierMC},
+	{"MX", IdentifierMX},
+	{"FF", IdentifierFF},
+}
+
+// Identifier is a USDOT or docket (MC, MX, FF) number. Its text form is the canonical "USDOT 1003306" or
+// "MC-133655", which is also how it's marshaled to JSON.
+type Identifier struct {
+	Type   IdentifierType
+	Number string
+}
+
+// ParseIdentifier parses forms such as "USDOT 1003306", "DOT#1003306", "MC-133655", "mx123" and "FF 1234". A bare
+// number is a USDOT number. Returns an *InvalidIdentifierError, matching ErrInvalidIdentifier, if s isn't one of
+// these forms.
+func ParseIdentifier(s string) (Identifier, error) {
+	return parseIdentifier(s, IdentifierUSDOT)
+}
+
+// parseIdentifier is ParseIdentifier with the type given to a bare number
+func parseIdentifier(s string, bare IdentifierType) (Identifier, error) {
+	id := Identifier{Type: bare, Number: strings.ToUpper(strings.TrimSpace(s))}
+	for _, p := range identifierPrefixes {
+		if strings.HasPrefix(id.Number, p.prefix) {
+			id.Type, id.Number = p.typ, strings.TrimLeft(id.Number[len(p.prefix):], "-# ")
+			break
+		}
+	}
+	if reason := invalidNumber(id.Number); reason != "" {
+		return Identifier{}, &InvalidIdentifierError{Type: id.Type, Value: s, Reason: reason}
+	}
+	return id, nil
+}
+
+// invalidNumber returns why number isn't a valid DOT or docket number, or "" if it is
+func invalidNumber(number string) string {
+	if number == "" {
+		return "empty"
+	}
+	if len(number) > maxIdentifierLength {
+		return fmt.Sprintf("longer than %d digits", maxIdentifierLength)
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return "must contain only digits"
+		}
+	}
+	return ""
+}
+
+// String returns the canonical form, e.g. "USDOT 1003306" or "MC-133655"
+func (id Identifier) String() string {
+	if id.Type == IdentifierUSDOT {
+		return string(id.Type) + " " + id.Number
+	}
+	return string(id.Type) + "-" + id.Number
+}
+
+// MarshalText encodes the identifier in its canonical form
+func (id Identifier) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText decodes any form accepted by ParseIdentifier
+func (id *Identifier) UnmarshalText(text []byte) error {
+	parsed, err := ParseIdentifier(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// queryParam returns the SAFER query_param used to look up the identifier. SAFER looks up MC and MX numbers by
+// their digits through its MC/MX search, and has no search for FF numbers.
+func (id Identifier) queryParam() (string, error) {
+	switch id.Type {
+	case IdentifierUSDOT:
+		return paramUSDOT, nil
+	case IdentifierMC, IdentifierMX:
+		return paramMCMX, nil
+	case IdentifierFF:
+		return "", &InvalidIdentifierError{Type: id.Type, Value: id.Number, Reason: "SAFER can't look up FF numbers"}
+	}
+	return "", &InvalidIdentifierError{Type: id.Type, Value: id.Number, Reason: "unknown identifier type"}
+}
+
+// InvalidIdentifierError is returned before any request is sent when a DOT or docket number isn't a valid
+// identifier. It matches errors.Is(err, ErrInvalidIdentifier).
+type InvalidIdentifierError struct {
+	// Type of identifier that was expected or found from its prefix
+	Type IdentifierType
+	// Value as given by the caller
+	Value string
+	// Reason the value was rejected
+	Reason string
+}
+
+func (e *InvalidIdentifierError) Error() string {
+	return fmt.Sprintf("invalid %s identifier %q: %s", e.Type, e.Value, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrInvalidIdentifier) match any *InvalidIdentifierError
+func (e *InvalidIdentifierError) Is(target error) bool {
+	return target == ErrInvalidIdentifier
+}
+
+// normalizeIdentifier parses a DOT or MC/MX number, stripping whitespace and a prefix for that kind of number such
+// as "MC-" or "USDOT ". A bare number is a USDOT or MC number. A prefix for another kind of number, or anything
+// else that isn't a digit, is rejected.
+func normalizeIdentifier(queryParam, value string) (Identifier, error) {
+	bare, expected := IdentifierUSDOT, "USDOT"
+	if queryParam == paramMCMX {
+		bare, expected = IdentifierMC, "MC or MX"
+	}
+	id, err := parseIdentifier(value, bare)
+	if err != nil {
+		return Identifier{}, err
+	}
+	param, err := id.queryParam()
+	if err != nil {
+		return Identifier{}, &InvalidIdentifierError{Type: id.Type, Value: value, Reason: err.(*InvalidIdentifierError).Reason}
+	}
+	if param != queryParam {
+		reason := fmt.Sprintf("%s number given where %s expected", id.Type, expected)
+		return Identifier{}, &InvalidIdentifierError{Type: id.Type, Value: value, Reason: reason}
+	}
+	return id, nil
+}
+
+// docketIdentifiers parses the MC/MX/FF numbers shown on a snapshot, skipping any that aren't recognized
+func docketIdentifiers(numbers []string) []Identifier {
+	ids := make([]Identifier, 0, len(numbers))
+	for _, number := range numbers {
+		if id, err := parseIdentifier(number, ""); err == nil && id.Type != "" && id.Type != IdentifierUSDOT {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Lookup - Get a company snapshot by any Identifier, such as one from ParseIdentifier or a snapshot's
+// DocketNumbers. USDOT numbers are looked up like GetCompanyByDOTNumber and MC/MX numbers like GetCompanyByMCMX.
+// FF numbers can't be looked up on SAFER and return ErrInvalidIdentifier.
+func (c *Client) Lookup(ctx context.Context, id Identifier) (*CompanySnapshot, error) {
+	return c.scraper.lookupIdentifier(ctx, id)
+}
+
+// lookupIdentifier gets the snapshot for id. SAFER's MC/MX search only takes digits, so the snapshot found for an MX number
+// must list that MX number, or it belongs to the carrier with the MC number of the same digits and
+// ErrCompanyNotFound is returned.
+func (s *scraper) lookupIdentifier(ctx context.Context, id Identifier) (*CompanySnapshot, error) {
+	queryParam, err := id.queryParam()
+	if err != nil {
+		return nil, err
+	}
+	if reason := invalidNumber(id.Number); reason != "" {
+		return nil, &InvalidIdentifierError{Type: id.Type, Value: id.Number, Reason: reason}
+	}
+	snapshot, err := s.scrapeCompanySnapshot(ctx, queryParam, id.Number)
+	if err != nil {
+		return nil, err
+	}
+	if id.Type == IdentifierMX && !hasDocketNumber(snapshot, id) {
+		return nil, ErrCompanyNotFound
+	}
+	return snapshot, nil
+}
+
+func hasDocketNumber(snapshot *CompanySnapshot, id Identifier) bool {
+	for _, docket := range snapshot.DocketNumbers {
+		if docket == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+package safer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestNormalizeIdentifier(t *testing.T) {
+	tests := []struct {
+		param   string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{paramUSDOT, "264184", "264184", false},
+		{paramUSDOT, " 264184\n", "264184", false},
+		{paramUSDOT, "USDOT 264184", "264184", false},
+		{paramUSDOT, "dot#264184", "264184", false},
+		{paramUSDOT, "00012345", "00012345", false},
+		{paramMCMX, "133655", "133655", false},
+		{paramMCMX, "MC-133655", "133655", false},
+		{paramMCMX, "mx 133655", "133655", false},
+		{paramUSDOT, "", "", true},
+		{paramUSDOT, "USDOT", "", true},
+		{paramUSDOT, "MC-133655", "", true},
+		{paramUSDOT, "264184&query_param=MC_MX", "", true},
+		{paramUSDOT, "2641 84", "", true},
+		{paramUSDOT, "123456789", "", true},
+		{paramMCMX, "FF-133655", "", true},
+		{paramMCMX, "-133655", "", true},
+		{paramMCMX, "1336５5", "", true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeIdentifier(tt.param, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeIdentifier(%q, %q) error = %v, wantErr %v", tt.param, tt.value, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidIdentifier) {
+			t.Errorf("normalizeIdentifier(%q, %q) error = %v, want ErrInvalidIdentifier", tt.param, tt.value, err)
+		}
+		if got.Number != tt.want {
+			t.Errorf("normalizeIdentifier(%q, %q) = %q, want %q", tt.param, tt.value, got.Number, tt.want)
+		}
+	}
+}
+
+func TestNormalizeIdentifier_WrongPrefix(t *testing.T) {
+	tests := []struct {
+		param string
+		value string
+		want  string
+	}{
+		{paramUSDOT, "MC-133655", "MC number given where USDOT expected"},
+		{paramUSDOT, "MX 1", "MX number given where USDOT expected"},
+		{paramMCMX, "USDOT 264184", "USDOT number given where MC or MX expected"},
+		{paramMCMX, "FF-1", "SAFER can't look up FF numbers"},
+	}
+	for _, tt := range tests {
+		_, err := normalizeIdentifier(tt.param, tt.value)
+		var invalid *InvalidIdentifierError
+		if !errors.As(err, &invalid) || invalid.Reason != tt.want {
+			t.Errorf("normalizeIdentifier(%q, %q) error = %v, want reason %q", tt.param, tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestClient_InvalidIdentifier(t *testing.T) {
+	var mu sync.Mutex
+	var queries []url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(readTestData("./testdata/snapshot-basic.html"))
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	_, err := c.GetCompanyByDOTNumber("264184&query_param=MC_MX")
+	var idErr *InvalidIdentifierError
+	if !errors.As(err, &idErr) || idErr.Type != paramUSDOT {
+		t.Errorf("GetCompanyByDOTNumber() error = %v, want an *InvalidIdentifierError", err)
+	}
+	if _, err := c.GetCompanyByMCMX("MC 13#3655"); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("GetCompanyByMCMX() error = %v, want ErrInvalidIdentifier", err)
+	}
+	if len(queries) != 0 {
+		t.Errorf("invalid identifiers sent %d requests, want 0", len(queries))
+	}
+
+	if _, err := c.GetCompanyByMCMX("MC-133655"); err != nil {
+		t.Fatalf("GetCompanyByMCMX should return no error, but got %v", err)
+	}
+	want := url.Values{
+		"searchType":   {"ANY"},
+		"query_type":   {"queryCarrierSnapshot"},
+		"query_param":  {paramMCMX},
+		"query_string": {"133655"},
+	}
+	if !reflect.DeepEqual(queries[0], want) {
+		t.Errorf("GetCompanyByMCMX() sent query %v, want %v", queries[0], want)
+	}
+
+	queries = nil
+	results := c.GetCompaniesByMCMXs(context.Background(), []string{"MC-133655", "bad", "133655"}, BatchOptions{})
+	if !errors.Is(results[1].Err, ErrInvalidIdentifier) || results[1].Query != "bad" {
+		t.Errorf("results[1] = %+v, want ErrInvalidIdentifier", results[1])
+	}
+	if results[0].Err != nil || results[0].Snapshot != results[2].Snapshot {
+		t.Errorf("prefixed and plain duplicates should share a snapshot")
+	}
+	if len(queries) != 1 {
+		t.Errorf("batch sent %d requests, want 1", len(queries))
+	}
+}
+
+func TestParseIdentifier(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Identifier
+		str     string
+		wantErr bool
+	}{
+		{"USDOT 1003306", Identifier{IdentifierUSDOT, "1003306"}, "USDOT 1003306", false},
+		{"dot#1003306", Identifier{IdentifierUSDOT, "1003306"}, "USDOT 1003306", false},
+		{"1003306", Identifier{IdentifierUSDOT, "1003306"}, "USDOT 1003306", false},
+		{"MC-133655", Identifier{IdentifierMC, "133655"}, "MC-133655", false},
+		{" mc 133655 ", Identifier{IdentifierMC, "133655"}, "MC-133655", false},
+		{"MX123", Identifier{IdentifierMX, "123"}, "MX-123", false},
+		{"FF-1234", Identifier{IdentifierFF, "1234"}, "FF-1234", false},
+		{"", Identifier{}, "", true},
+		{"MC-", Identifier{}, "", true},
+		{"XX-1234", Identifier{}, "", true},
+		{"MC-12a", Identifier{}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseIdentifier(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseIdentifier(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, ErrInvalidIdentifier) {
+				t.Errorf("ParseIdentifier(%q) error = %v, want ErrInvalidIdentifier", tt.in, err)
+			}
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseIdentifier(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("ParseIdentifier(%q).String() = %q, want %q", tt.in, got.String(), tt.str)
+		}
+	}
+}
+
+func TestIdentifier_JSON(t *testing.T) {
+	ids := []Identifier{{IdentifierUSDOT, "264184"}, {IdentifierMC, "133655"}}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["USDOT 264184","MC-133655"]`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+	var got []Identifier
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal should return no error, but got %v", err)
+	}
+	if !reflect.DeepEqual(got, ids) {
+		t.Errorf("json.Unmarshal() = %v, want %v", got, ids)
+	}
+	if err := json.Unmarshal([]byte(`["MC-"]`), &got); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("json.Unmarshal() error = %v, want ErrInvalidIdentifier", err)
+	}
+}
+
+func TestDocketIdentifiers(t *testing.T) {
+	got := docketIdentifiers([]string{"MC-133655", "FF-1234", "MX-98765", "12345", "MC-PENDING"})
+	want := []Identifier{{IdentifierMC, "133655"}, {IdentifierFF, "1234"}, {IdentifierMX, "98765"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("docketIdentifiers() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_Lookup(t *testing.T) {
+	var params []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params = append(params, r.URL.Query().Get("query_param")+":"+r.URL.Query().Get("query_string"))
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(readTestData("./testdata/snapshot-basic.html"))
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	for _, in := range []string{"USDOT 264184", "MC-133655"} {
+		id, err := ParseIdentifier(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Lookup(context.Background(), id); err != nil {
+			t.Errorf("Lookup(%v) should return no error, but got %v", id, err)
+		}
+	}
+	want := []string{"USDOT:264184", "MC_MX:133655"}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Lookup() sent %v, want %v", params, want)
+	}
+
+	for _, id := range []Identifier{{}, {Type: "CA", Number: "1"}, {Type: IdentifierMC, Number: "1&x=2"}} {
+		if _, err := c.Lookup(context.Background(), id); !errors.Is(err, ErrInvalidIdentifier) {
+			t.Errorf("Lookup(%#v) error = %v, want ErrInvalidIdentifier", id, err)
+		}
+	}
+	if len(params) != len(want) {
+		t.Errorf("invalid identifiers sent %d requests, want 0", len(params)-len(want))
+	}
+}
+
+func TestClient_Lookup_OtherDocketTypes(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// SAFER's MC/MX search only takes digits, so every docket number with these digits finds MC-133655
+		requests++
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(readTestData("./testdata/snapshot-basic.html"))
+	}))
+	defer ts.Close()
+	c := NewClient(WithBaseURL(ts.URL))
+
+	ff := Identifier{Type: IdentifierFF, Number: "133655"}
+	if got, err := c.Lookup(context.Background(), ff); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("Lookup(%v) = %v, %v, want ErrInvalidIdentifier", ff, got, err)
+	}
+	if _, err := c.GetCompanyByMCMX("FF-133655"); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("GetCompanyByMCMX(FF-133655) error = %v, want ErrInvalidIdentifier", err)
+	}
+	if requests != 0 {
+		t.Errorf("FF lookups sent %d requests, want 0", requests)
+	}
+
+	mx := Identifier{Type: IdentifierMX, Number: "133655"}
+	if got, err := c.Lookup(context.Background(), mx); err != ErrCompanyNotFound {
+		t.Errorf("Lookup(%v) = %v, %v, want ErrCompanyNotFound", mx, got, err)
+	}
+	if got, err := c.GetCompanyByMCMX("MX-133655"); err != ErrCompanyNotFound {
+		t.Errorf("GetCompanyByMCMX(MX-133655) = %v, %v, want ErrCompanyNotFound", got, err)
+	}
+}
@@ -0,0 +1,130 @@
+package safer
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxInterstitialText is the most of a page's text read to classify it. Interstitial pages are short and say
+// what they are near the top.
+const maxInterstitialText = 4096
+
+// markers of the interstitial pages SAFER and its firewall send with a 200 status in place of the requested page.
+// Only a page's title and these markers are matched, never phrases anywhere in its text, since a search page
+// without results echoes the query, which could be anything.
+var (
+	// rejectedRegex finds the firewall's request rejected sentence and the support ID it gives to quote when
+	// asking to be unblocked
+	rejectedRegex = regexp.MustCompile(`the requested url was rejected\..*your support id is:?\s*([0-9a-z-]+)`)
+	// captchaClasses are the classes of the elements captcha widgets render into
+	captchaClasses = []string{"g-recaptcha", "h-captcha", "cf-turnstile"}
+	// captchaScripts are the script and frame sources of captcha widgets
+	captchaScripts = []string{"google.com/recaptcha/", "recaptcha.net/recaptcha/", "hcaptcha.com/", "challenges.cloudflare.com/"}
+	// captchaTitles are title prefixes of challenge pages
+	captchaTitles = []string{"just a moment", "attention required"}
+	// maintenanceTitles are title phrases of maintenance notices
+	maintenanceTitles = []string{"maintenance", "temporarily unavailable", "service unavailable"}
+)
+
+// pageMarkers holds the parts of a page used to recognize it as an interstitial
+type pageMarkers struct {
+	title   strings.Builder
+	text    strings.Builder // up to maxInterstitialText of the page's text, leaving out scripts, styles and the title
+	captcha bool            // the page has a captcha widget
+}
+
+// element records the markers in an element's tag and attributes
+func (m *pageMarkers) element(tag, key, val string) {
+	switch {
+	case key == "class" && containsAny(strings.ToLower(val), captchaClasses):
+		m.captcha = true
+	case key == "src" && (tag == "script" || tag == "iframe") && containsAny(strings.ToLower(val), captchaScripts):
+		m.captcha = true
+	}
+}
+
+// addText adds text found outside scripts, styles and the title
+func (m *pageMarkers) addText(text string) {
+	if m.text.Len() < maxInterstitialText {
+		m.text.WriteString(text)
+		m.text.WriteByte(' ')
+	}
+}
+
+// classify returns an error matching ErrBlocked for a request rejected or captcha page, ErrMaintenance for a
+// maintenance notice, or nil for any other page
+func (m *pageMarkers) classify() error {
+	title := strings.ToLower(strings.Join(strings.Fields(m.title.String()), " "))
+	text := strings.ToLower(strings.Join(strings.Fields(m.text.String()), " "))
+	switch rejected := rejectedRegex.FindStringSubmatch(text); {
+	case title == "request rejected" || rejected != nil:
+		reason := "request rejected page"
+		if rejected != nil {
+			reason += ", support ID " + rejected[1]
+		}
+		return &kindError{kind: ErrBlocked, err: errors.New(reason)}
+	case m.captcha || hasAnyPrefix(title, captchaTitles):
+		return &kindError{kind: ErrBlocked, err: errors.New("captcha page")}
+	case containsAny(title, maintenanceTitles) && !strings.HasPrefix(title, "safer web - company snapshot"):
+		// snapshot titles end with the carrier's name, which could hold any of these phrases
+		return &kindError{kind: ErrMaintenance, err: errors.New("maintenance page")}
+	}
+	return nil
+}
+
+// classifyPage checks whether a page that isn't a snapshot or search results page is one of SAFER's
+// interstitials. Returns an error matching ErrBlocked for a request rejected or captcha page, ErrMaintenance for a
+// maintenance notice, or nil for any other page.
+func classifyPage(root *html.Node) error {
+	var m pageMarkers
+	addPageMarkers(&m, root)
+	return m.classify()
+}
+
+// addPageMarkers records the markers of n and its children in m
+func addPageMarkers(m *pageMarkers, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		m.addText(n.Data)
+		return
+	case html.ElementNode:
+		for _, attr := range n.Attr {
+			m.element(n.Data, attr.Key, attr.Val)
+		}
+		switch n.Data {
+		case "title":
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.TextNode {
+					m.title.WriteString(c.Data)
+				}
+			}
+			return
+		case "script", "style":
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		addPageMarkers(m, c)
+	}
+}
+
+func containsAny(s string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(s, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
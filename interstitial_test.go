@@ -0,0 +1,142 @@
+package safer
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+)
+
+// interstitialTests use synthetic pages, not captures, see testdata/synthetic/README.md
+var interstitialTests = []struct {
+	file    string
+	want    error
+	message string
+}{
+	{"./testdata/synthetic/request-rejected.html", ErrBlocked, "support ID 14207736451928475162"},
+	{"./testdata/synthetic/captcha.html", ErrBlocked, "captcha page"},
+	{"./testdata/synthetic/maintenance.html", ErrMaintenance, "maintenance page"},
+}
+
+func TestParseCompanySnapshot_Interstitials(t *testing.T) {
+	for _, tt := range interstitialTests {
+		data, err := os.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		snapshot, err := ParseCompanySnapshot(bytes.NewReader(data))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: ParseCompanySnapshot() error = %v, want %v", tt.file, err, tt.want)
+		} else if !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("%s: ParseCompanySnapshot() error = %q, want it to contain %q", tt.file, err, tt.message)
+		}
+		if snapshot != nil {
+			t.Errorf("%s: ParseCompanySnapshot() = %v, want nil", tt.file, snapshot)
+		}
+		if _, _, err := ParseCompanySnapshotWithReport(bytes.NewReader(data), ParseOptions{Strict: true}); !errors.Is(err, tt.want) {
+			t.Errorf("%s: strict ParseCompanySnapshotWithReport() error = %v, want %v", tt.file, err, tt.want)
+		}
+	}
+}
+
+func TestParseSearchResults_Interstitials(t *testing.T) {
+	for _, tt := range interstitialTests {
+		data, err := os.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseSearchResults(bytes.NewReader(data)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: ParseSearchResults() error = %v, want %v", tt.file, err, tt.want)
+		}
+		node, err := htmlquery.Parse(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseSearchResultsNode(node); !errors.Is(err, tt.want) {
+			t.Errorf("%s: ParseSearchResultsNode() error = %v, want %v", tt.file, err, tt.want)
+		}
+	}
+}
+
+func TestClient_Interstitials(t *testing.T) {
+	for _, tt := range interstitialTests {
+		data := readTestData(tt.file)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write(data)
+		}))
+		c := NewClient(WithBaseURL(ts.URL))
+		if _, err := c.GetCompanyByDOTNumber("264184"); !errors.Is(err, tt.want) {
+			t.Errorf("%s: GetCompanyByDOTNumber() error = %v, want %v", tt.file, err, tt.want)
+		}
+		if _, err := c.SearchCompaniesByName("schneider"); !errors.Is(err, tt.want) {
+			t.Errorf("%s: SearchCompaniesByName() error = %v, want %v", tt.file, err, tt.want)
+		} else if errors.Is(err, ErrUpstreamUnavailable) {
+			t.Errorf("%s: SearchCompaniesByName() error = %v, should not match ErrUpstreamUnavailable", tt.file, err)
+		}
+		ts.Close()
+	}
+}
+
+// zeroResultsPage is a search results page without results, which echoes the query
+func zeroResultsPage(query string) string {
+	return `<HEAD><TITLE>SAFER WEB - Select Company</TITLE></HEAD>
+<TABLE BORDER=0 ALIGN=CENTER><TR><TD><FONT size="4"><B><I>Possible Keyword Matches &nbsp;</I></B></FONT></TD></TR></TABLE>
+<P>No records matching <B>` + query + `</B> were found.</P>`
+}
+
+func TestClassifyPage(t *testing.T) {
+	tests := []struct {
+		page string
+		want error
+	}{
+		{zeroResultsPage("ACCESS DENIED TRUCKING"), nil},
+		{zeroResultsPage("MAINTENANCE SERVICES"), nil},
+		{zeroResultsPage("SECURITY CHECK"), nil},
+		{zeroResultsPage("CAPTCHA LOGISTICS"), nil},
+		{zeroResultsPage("REQUEST REJECTED"), nil},
+		{"<title>SAFER Web - Company Snapshot ACME MAINTENANCE LLC</title>", nil},
+		{"<title>Request Rejected</title>", ErrBlocked},
+		{"<p>The requested URL was rejected. Please consult with your administrator.<br>Your support ID is: 123</p>", ErrBlocked},
+		{"<title>Just a moment...</title>", ErrBlocked},
+		{`<form><div class="h-captcha" data-sitekey="x"></div></form>`, ErrBlocked},
+		{`<script src="https://challenges.cloudflare.com/turnstile/v0/api.js"></script>`, ErrBlocked},
+		{"<title>SAFER Web - System Maintenance</title>", ErrMaintenance},
+		{"<title>Service Unavailable</title>", ErrMaintenance},
+	}
+	for _, tt := range tests {
+		root, err := htmlquery.Parse(strings.NewReader(tt.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := classifyPage(root); !errors.Is(got, tt.want) || (got == nil) != (tt.want == nil) {
+			t.Errorf("classifyPage(%q) = %v, want %v", tt.page, got, tt.want)
+		}
+		scanner := NewSearchResultScanner(strings.NewReader(tt.page))
+		for scanner.Scan() {
+		}
+		if got := scanner.Err(); !errors.Is(got, tt.want) || (got == nil) != (tt.want == nil) {
+			t.Errorf("SearchResultScanner.Err() for %q = %v, want %v", tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestClient_SearchWithoutResults(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(zeroResultsPage(r.URL.Query().Get("searchstring"))))
+	}))
+	defer ts.Close()
+	c := NewClient(WithBaseURL(ts.URL))
+	for _, query := range []string{"ACCESS DENIED TRUCKING", "MAINTENANCE SERVICES", "SECURITY CHECK"} {
+		results, err := c.SearchCompaniesByName(query)
+		if err != nil || len(results) != 0 {
+			t.Errorf("SearchCompaniesByName(%q) = %v, %v, want no results", query, results, err)
+		}
+	}
+}
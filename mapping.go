@@ -0,0 +1,350 @@
+package safer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Parsers applied to the text a FieldMapping selects
+const (
+	// ParserText keeps the first non-blank text
+	ParserText = "text"
+	// ParserTexts keeps every text, for lists such as MC/MX/FF numbers
+	ParserTexts = "texts"
+	// ParserInt parses the first non-blank text as an integer, ignoring thousands separators
+	ParserInt = "int"
+	// ParserPercent parses the first non-blank text as a percentage (e.g. "13.6%" is 0.136)
+	ParserPercent = "percent"
+	// ParserDate parses the first non-blank text as an MM/DD/YYYY date
+	ParserDate = "date"
+	// ParserAddress parses the texts as the lines of an Address
+	ParserAddress = "address"
+	// ParserMileageYear parses the first non-blank text as an MCS-150 mileage and year (e.g. "1,000 (2020)")
+	ParserMileageYear = "mileage_year"
+	// ParserCheckboxes reads the labels of the checked rows of a checkbox grid, so its path must select the rows
+	ParserCheckboxes = "checkboxes"
+)
+
+// Mapping describes where each field of a company snapshot is found on the page. DefaultMapping matches the
+// current SAFER layout. When SAFER changes its markup, a Mapping loaded from JSON with LoadMapping can patch the
+// affected selectors without waiting for a new release of this package.
+//
+// All paths are xpath expressions. A leading "/" is relative to the node the path is applied to: the document
+// for NotFound, Inactive and Source, the Source node for sections, and the section (or its row) for fields.
+type Mapping struct {
+	NotFound string           `json:"not_found"`
+	Inactive string           `json:"inactive"`
+	Source   string           `json:"source"`
+	Sections []SectionMapping `json:"sections"`
+	Fields   []FieldMapping   `json:"fields"`
+}
+
+// SectionMapping locates a section of the page. A section that isn't found is reported in
+// ParseReport.MissingSections, unless OptionalIf is set and matches.
+//
+// The fields of a Labeled section are found by the label cell before their value rather than by path, the way the
+// general info table is parsed.
+type SectionMapping struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	OptionalIf string `json:"optional_if,omitempty"`
+	Labeled    bool   `json:"labeled,omitempty"`
+}
+
+// FieldMapping locates a single snapshot field, named by its JSON name with nested fields joined by a dot (e.g.
+// "legal_name", "us_crashes.fatal"), and names the parser applied to the text it selects.
+//
+// In a labeled section, Label is the text of the field's label (e.g. "Legal Name:") and Path, if set, is applied
+// to the value cell. Without a Path, the text of the value cell (or its bolded text) is used. Otherwise Path is
+// applied to the section, or to its Row'th (1-based) tr row when Row is set.
+type FieldMapping struct {
+	Field   string `json:"field"`
+	Section string `json:"section"`
+	Label   string `json:"label,omitempty"`
+	Row     int    `json:"row,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Parser  string `json:"parser"`
+}
+
+// DefaultMapping returns the mapping for the current SAFER company snapshot page. Each call returns a new copy
+// that may be modified freely.
+func DefaultMapping() *Mapping {
+	m := &Mapping{
+		NotFound: snapshotNotFoundXpath,
+		Inactive: snapshotInactiveXpath,
+		Source:   srcTableXpath,
+		Sections: []SectionMapping{
+			{Name: SectionLatestUpdate, Path: latestUpdateXpath},
+			{Name: SectionGeneralInfo, Path: tableGeneralInfoXpath, Labeled: true},
+			{Name: SectionUSInspections, Path: tableUSInspectionXpath},
+			{Name: SectionUSCrashes, Path: tableUSCrashXpath},
+			{Name: SectionCanadaInspections, Path: tableCanadaInspectionXpath},
+			{Name: SectionCanadaCrashes, Path: tableCanadaCrashXpath},
+			// unrated carriers (e.g. out-of-service) have the heading but no table
+			{Name: SectionSafetyRating, Path: tableSafetyRatingXpath, OptionalIf: safetyRatingHeadingXpath},
+		},
+		Fields: []FieldMapping{
+			{Field: "latest_update_date", Section: SectionLatestUpdate, Path: "/text()", Parser: ParserDate},
+
+			{Field: "entity_type", Section: SectionGeneralInfo, Label: "Entity Type:", Parser: ParserText},
+			{Field: "operating_status", Section: SectionGeneralInfo, Label: "Operating Status:", Parser: ParserText},
+			{Field: "out_of_service_date", Section: SectionGeneralInfo, Label: "Out of Service Date:", Parser: ParserDate},
+			{Field: "legal_name", Section: SectionGeneralInfo, Label: "Legal Name:", Parser: ParserText},
+			{Field: "dba_name", Section: SectionGeneralInfo, Label: "DBA Name:", Parser: ParserText},
+			{Field: "physical_address", Section: SectionGeneralInfo, Label: "Physical Address:", Path: "/text()", Parser: ParserAddress},
+			{Field: "phone", Section: SectionGeneralInfo, Label: "Phone:", Parser: ParserText},
+			{Field: "mailing_address", Section: SectionGeneralInfo, Label: "Mailing Address:", Path: "/text()", Parser: ParserAddress},
+			{Field: "dot_number", Section: SectionGeneralInfo, Label: "USDOT Number:", Parser: ParserText},
+			{Field: "state_carrier_id", Section: SectionGeneralInfo, Label: "State Carrier ID Number:", Parser: ParserText},
+			{Field: "mc_mx_ff_numbers", Section: SectionGeneralInfo, Label: "MC/MX/FF Number(s):", Path: "/a/text()", Parser: ParserTexts},
+			{Field: "duns_number", Section: SectionGeneralInfo, Label: "DUNS Number:", Parser: ParserText},
+			{Field: "power_units", Section: SectionGeneralInfo, Label: "Power Units:", Parser: ParserInt},
+			{Field: "drivers", Section: SectionGeneralInfo, Label: "Drivers:", Parser: ParserInt},
+			{Field: "mcs_150_form_date", Section: SectionGeneralInfo, Label: "MCS-150 Form Date:", Parser: ParserDate},
+			{Field: "mcs_150_mileage", Section: SectionGeneralInfo, Label: "MCS-150 Mileage (Year):", Parser: ParserMileageYear},
+			{Field: "operation_classification", Section: SectionGeneralInfo, Label: "Operation Classification:", Path: checkboxRowsXpath, Parser: ParserCheckboxes},
+			{Field: "carrier_operation", Section: SectionGeneralInfo, Label: "Carrier Operation:", Path: checkboxRowsXpath, Parser: ParserCheckboxes},
+			{Field: "cargo_carried", Section: SectionGeneralInfo, Label: "Cargo Carried:", Path: checkboxRowsXpath, Parser: ParserCheckboxes},
+		},
+	}
+	m.Fields = append(m.Fields, inspectionFieldMappings(SectionUSInspections, "us_vehicle_inspections", 1, true)...)
+	m.Fields = append(m.Fields, inspectionFieldMappings(SectionUSInspections, "us_driver_inspections", 2, true)...)
+	m.Fields = append(m.Fields, inspectionFieldMappings(SectionUSInspections, "us_hazmat_inspections", 3, true)...)
+	m.Fields = append(m.Fields, inspectionFieldMappings(SectionUSInspections, "us_iep_inspections", 4, true)...)
+	m.Fields = append(m.Fields, crashFieldMappings(SectionUSCrashes, "us_crashes")...)
+	m.Fields = append(m.Fields, inspectionFieldMappings(SectionCanadaInspections, "canada_vehicle_inspections", 1, false)...)
+	m.Fields = append(m.Fields, inspectionFieldMappings(SectionCanadaInspections, "canada_driver_inspections", 2, false)...)
+	m.Fields = append(m.Fields, crashFieldMappings(SectionCanadaCrashes, "canada_crashes")...)
+	m.Fields = append(m.Fields,
+		FieldMapping{Field: "safety.rating_date", Section: SectionSafetyRating, Row: 2, Path: "/td[1]/text()", Parser: ParserDate},
+		FieldMapping{Field: "safety.review_date", Section: SectionSafetyRating, Row: 2, Path: "/td[2]/text()", Parser: ParserDate},
+		FieldMapping{Field: "safety.rating", Section: SectionSafetyRating, Row: 3, Path: "/td[1]/text()", Parser: ParserText},
+		FieldMapping{Field: "safety.type", Section: SectionSafetyRating, Row: 3, Path: "/td[2]/text()", Parser: ParserText},
+	)
+	return m
+}
+
+// inspectionFieldMappings maps an inspection summary held in column col of an inspection table. Only the US
+// table has a national average row.
+func inspectionFieldMappings(section, prefix string, col int, nationalAverage bool) []FieldMapping {
+	td := fmt.Sprintf("/td[%d]", col)
+	fields := []FieldMapping{
+		{Field: prefix + ".inspections", Section: section, Row: 2, Path: td + "/text()", Parser: ParserInt},
+		{Field: prefix + ".out_of_service", Section: section, Row: 3, Path: td + "/text()", Parser: ParserInt},
+		{Field: prefix + ".out_of_service_pct", Section: section, Row: 4, Path: td + "/text()", Parser: ParserPercent},
+	}
+	if nationalAverage {
+		fields = append(fields, FieldMapping{Field: prefix + ".national_average", Section: section, Row: 5, Path: td + "/font/text()", Parser: ParserPercent})
+	}
+	return fields
+}
+
+// crashFieldMappings maps the crash summary in the second row of a crash table
+func crashFieldMappings(section, prefix string) []FieldMapping {
+	return []FieldMapping{
+		{Field: prefix + ".fatal", Section: section, Row: 2, Path: "/td[1]/text()", Parser: ParserInt},
+		{Field: prefix + ".injury", Section: section, Row: 2, Path: "/td[2]/text()", Parser: ParserInt},
+		{Field: prefix + ".tow", Section: section, Row: 2, Path: "/td[3]/text()", Parser: ParserInt},
+		{Field: prefix + ".total", Section: section, Row: 2, Path: "/td[4]/text()", Parser: ParserInt},
+	}
+}
+
+// LoadMapping reads a JSON override set and applies it to DefaultMapping with Merge. The overrides only need to
+// hold the sections and fields that differ from the default, each in full, for example:
+//
+//	{"sections": [{"name": "us_crashes", "path": "/center[5]/table/tbody"}]}
+//
+// The merged mapping is validated before it's returned.
+func LoadMapping(r io.Reader) (*Mapping, error) {
+	var overrides Mapping
+	if err := json.NewDecoder(r).Decode(&overrides); err != nil {
+		return nil, err
+	}
+	m := DefaultMapping().Merge(&overrides)
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Merge returns a copy of m with the overrides applied. Non-empty page selectors replace m's. Sections and
+// fields replace the one in m with the same name as a whole, or are added if m has none. Settings left out of an
+// override aren't kept from m: overriding only the path of the labeled general_info section also drops Labeled,
+// which leaves its fields without a section that can find them, so copy the entry from DefaultMapping and change
+// what differs.
+func (m *Mapping) Merge(overrides *Mapping) *Mapping {
+	merged := &Mapping{
+		NotFound: m.NotFound,
+		Inactive: m.Inactive,
+		Source:   m.Source,
+		Sections: append([]SectionMapping(nil), m.Sections...),
+		Fields:   append([]FieldMapping(nil), m.Fields...),
+	}
+	if overrides.NotFound != "" {
+		merged.NotFound = overrides.NotFound
+	}
+	if overrides.Inactive != "" {
+		merged.Inactive = overrides.Inactive
+	}
+	if overrides.Source != "" {
+		merged.Source = overrides.Source
+	}
+sections:
+	for _, override := range overrides.Sections {
+		for i, section := range merged.Sections {
+			if section.Name == override.Name {
+				merged.Sections[i] = override
+				continue sections
+			}
+		}
+		merged.Sections = append(merged.Sections, override)
+	}
+fields:
+	for _, override := range overrides.Fields {
+		for i, field := range merged.Fields {
+			if field.Field == override.Field {
+				merged.Fields[i] = override
+				continue fields
+			}
+		}
+		merged.Fields = append(merged.Fields, override)
+	}
+	return merged
+}
+
+// Validate reports the first problem that would keep m from parsing a page: an xpath that doesn't compile, a
+// field or parser that doesn't exist, a parser that doesn't suit its field, or a field outside of any section.
+func (m *Mapping) Validate() error {
+	_, err := compileMapping(m)
+	return err
+}
+
+// compiledMapping is a validated Mapping with its selectors precompiled, ready to parse pages
+type compiledMapping struct {
+	notFound *selector
+	inactive *selector
+	source   *selector
+	sections []compiledSection
+	fields   int // the number of fields, to size a ParseReport
+}
+
+type compiledSection struct {
+	name       string
+	path       *selector
+	optionalIf *selector
+	labeled    bool
+	fields     []compiledField
+	// labels maps the labelKey of each field's label to its index in fields, for labeled sections
+	labels map[string]int
+}
+
+type compiledField struct {
+	name  string
+	label string // the label before the field's value, for labeled sections
+	row   int
+	path  *selector // nil for a labeled field read from its value cell
+	set   func(s *CompanySnapshot, v interface{})
+	parse fieldParser
+}
+
+// defaultCompiledMapping is used when no Mapping is given
+var defaultCompiledMapping = mustCompileMapping(DefaultMapping())
+
+func mustCompileMapping(m *Mapping) *compiledMapping {
+	cm, err := compileMapping(m)
+	if err != nil {
+		panic(err)
+	}
+	return cm
+}
+
+func compileMapping(m *Mapping) (*compiledMapping, error) {
+	cm := new(compiledMapping)
+	pages := []struct {
+		name string
+		path string
+		sel  **selector
+	}{
+		{"not_found", m.NotFound, &cm.notFound},
+		{"inactive", m.Inactive, &cm.inactive},
+		{"source", m.Source, &cm.source},
+	}
+	for _, page := range pages {
+		if page.path == "" {
+			return nil, fmt.Errorf("mapping %s: path is required", page.name)
+		}
+		sel, err := compileSelector(page.path)
+		if err != nil {
+			return nil, fmt.Errorf("mapping %s: %v", page.name, err)
+		}
+		*page.sel = sel
+	}
+	sections := make(map[string]int, len(m.Sections))
+	for _, section := range m.Sections {
+		if section.Path == "" {
+			return nil, fmt.Errorf("mapping section %q: path is required", section.Name)
+		}
+		cs := compiledSection{name: section.Name, labeled: section.Labeled}
+		var err error
+		if cs.path, err = compileSelector(section.Path); err != nil {
+			return nil, fmt.Errorf("mapping section %q: %v", section.Name, err)
+		}
+		if section.OptionalIf != "" {
+			if cs.optionalIf, err = compileSelector(section.OptionalIf); err != nil {
+				return nil, fmt.Errorf("mapping section %q: optional_if: %v", section.Name, err)
+			}
+		}
+		if section.Labeled {
+			cs.labels = make(map[string]int)
+		}
+		sections[section.Name] = len(cm.sections)
+		cm.sections = append(cm.sections, cs)
+	}
+	for _, f := range m.Fields {
+		field, ok := snapshotFields[f.Field]
+		if !ok {
+			return nil, fmt.Errorf("mapping field %q: unknown field", f.Field)
+		}
+		parse, ok := fieldParsers[f.Parser]
+		if !ok {
+			return nil, fmt.Errorf("mapping field %q: unknown parser %q", f.Field, f.Parser)
+		}
+		if f.Parser != field.parser {
+			return nil, fmt.Errorf("mapping field %q: parser %q can't set the field, use %q", f.Field, f.Parser, field.parser)
+		}
+		i, ok := sections[f.Section]
+		if !ok {
+			return nil, fmt.Errorf("mapping field %q: unknown section %q", f.Field, f.Section)
+		}
+		cs := &cm.sections[i]
+		if cs.labeled && f.Label == "" {
+			return nil, fmt.Errorf("mapping field %q: label is required in labeled section %q", f.Field, f.Section)
+		}
+		if !cs.labeled && f.Path == "" {
+			return nil, fmt.Errorf("mapping field %q: path is required", f.Field)
+		}
+		cf := compiledField{name: f.Field, label: f.Label, row: f.Row, set: field.set, parse: parse}
+		if f.Path != "" {
+			var err error
+			if cf.path, err = compileSelector(f.Path); err != nil {
+				return nil, fmt.Errorf("mapping field %q: %v", f.Field, err)
+			}
+		}
+		if cs.labeled {
+			cs.labels[labelKey(f.Label)] = len(cs.fields)
+		}
+		cs.fields = append(cs.fields, cf)
+		cm.fields++
+	}
+	return cm, nil
+}
+
+// WithMapping sets the mapping used to parse company snapshot pages, such as one loaded with LoadMapping to
+// patch a selector after a SAFER markup change. The mapping is compiled when the client is built, so it must not
+// be modified afterwards. If it's invalid, every snapshot lookup returns the error from Validate.
+func WithMapping(m *Mapping) Option {
+	return func(o *options) {
+		o.parseOptions.Mapping = m
+	}
+}
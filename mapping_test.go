@@ -0,0 +1,125 @@
+package safer
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// redesignOverrides patches the default mapping for redesignedSnapshot, where the section tables are no longer
+// wrapped in <center>
+const redesignOverrides = `{
+	"sections": [
+		{"name": "general_info", "path": "/div[1]/table/tbody", "labeled": true},
+		{"name": "us_inspections", "path": "/div[3]/table/tbody"},
+		{"name": "us_crashes", "path": "/div[4]/table/tbody"},
+		{"name": "canada_inspections", "path": "/div[6]/table/tbody"},
+		{"name": "canada_crashes", "path": "/div[7]/table/tbody"},
+		{"name": "safety_rating", "path": "/div[9]/table/tbody", "optional_if": ".//a[@name='Safety']"}
+	]
+}`
+
+func TestDefaultMapping(t *testing.T) {
+	m := DefaultMapping()
+	if err := m.Validate(); err != nil {
+		t.Fatalf("DefaultMapping().Validate() = %v, want nil", err)
+	}
+	mapped := make(map[string]bool)
+	for _, f := range m.Fields {
+		mapped[f.Field] = true
+	}
+	// SAFER doesn't show a national average for Canadian inspections
+	mapped["canada_vehicle_inspections.national_average"] = true
+	mapped["canada_driver_inspections.national_average"] = true
+	for name := range snapshotFields {
+		if !mapped[name] {
+			t.Errorf("DefaultMapping() doesn't map field %q", name)
+		}
+	}
+
+	// the default can be exported, edited and loaded back
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadMapping(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("LoadMapping should return no error, but got %v", err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("LoadMapping() = %v, want %v", loaded, m)
+	}
+}
+
+func TestLoadMapping_Overrides(t *testing.T) {
+	want, err := ParseCompanySnapshot(bytes.NewReader(readTestData("./testdata/snapshot-basic.html")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadMapping(strings.NewReader(redesignOverrides))
+	if err != nil {
+		t.Fatalf("LoadMapping should return no error, but got %v", err)
+	}
+	if len(m.Fields) != len(DefaultMapping().Fields) {
+		t.Errorf("LoadMapping() should keep the default fields, got %d want %d", len(m.Fields), len(DefaultMapping().Fields))
+	}
+
+	got, report, err := ParseCompanySnapshotWithReport(bytes.NewReader(redesignedSnapshot()), ParseOptions{Strict: true, Mapping: m})
+	if err != nil {
+		t.Fatalf("ParseCompanySnapshotWithReport should return no error, but got %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCompanySnapshotWithReport() = %v, want %v", got, want)
+	}
+	if len(report.Warnings) > 0 {
+		t.Errorf("Warnings = %v, want none", report.Warnings)
+	}
+}
+
+func TestLoadMapping_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"bad json", `{"sections": [`},
+		{"bad section xpath", `{"sections": [{"name": "us_crashes", "path": "/div[4"}]}`},
+		{"bad field xpath", `{"fields": [{"field": "us_crashes.fatal", "section": "us_crashes", "row": 2, "path": "td[", "parser": "int"}]}`},
+		{"unknown field", `{"fields": [{"field": "us_crashes.minor", "section": "us_crashes", "row": 2, "path": "/td[5]/text()", "parser": "int"}]}`},
+		{"unknown parser", `{"fields": [{"field": "us_crashes.fatal", "section": "us_crashes", "row": 2, "path": "/td[1]/text()", "parser": "float"}]}`},
+		{"wrong parser", `{"fields": [{"field": "us_crashes.fatal", "section": "us_crashes", "row": 2, "path": "/td[1]/text()", "parser": "date"}]}`},
+		{"unknown section", `{"fields": [{"field": "us_crashes.fatal", "section": "crashes", "row": 2, "path": "/td[1]/text()", "parser": "int"}]}`},
+		{"missing label", `{"fields": [{"field": "legal_name", "section": "general_info", "path": "/text()", "parser": "text"}]}`},
+		{"missing path", `{"fields": [{"field": "us_crashes.fatal", "section": "us_crashes", "row": 2, "parser": "int"}]}`},
+		{"partial section", `{"sections": [{"name": "general_info", "path": "/center[1]/table/tbody"}]}`},
+	}
+	for _, tt := range tests {
+		if _, err := LoadMapping(strings.NewReader(tt.json)); err == nil {
+			t.Errorf("%s: LoadMapping should return an error", tt.name)
+		}
+	}
+}
+
+func TestClient_WithMapping(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(redesignedSnapshot())
+	}))
+	defer ts.Close()
+
+	m, err := LoadMapping(strings.NewReader(redesignOverrides))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(WithBaseURL(ts.URL), WithStrictParsing(), WithMapping(m))
+	snapshot, err := c.GetCompanyByDOTNumber("264184")
+	if err != nil {
+		t.Fatalf("GetCompanyByDOTNumber should return no error, but got %v", err)
+	}
+	if snapshot.USCrashes.Total != 837 || snapshot.LegalName != "SCHNEIDER NATIONAL CARRIERS INC" {
+		t.Errorf("GetCompanyByDOTNumber() = %v, want the patched sections parsed", snapshot)
+	}
+}
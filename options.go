@@ -0,0 +1,83 @@
+package safer
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Option configures a Client built with NewClient
+type Option func(*options)
+
+type options struct {
+	httpClient  *http.Client
+	baseURL     string
+	headers     http.Header
+	timeout     time.Duration
+	retryPolicy RetryPolicy
+	rateLimiter *rateLimiter
+	cache       Cache
+	cacheTTL    time.Duration
+
+	parseOptions    ParseOptions
+	parseReportHook func(*ParseReport)
+}
+
+// WithHTTPClient sets the http.Client used for all requests to SAFER. Use this to route requests through a proxy
+// or a custom transport. Defaults to a client with a 30 second timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithBaseURL sets the scheme and host that requests are sent to (e.g. "https://safer-mirror.example.com").
+// Defaults to "https://safer.fmcsa.dot.gov".
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return WithHeader("User-Agent", userAgent)
+}
+
+// WithHeader sets a header sent with every request, replacing any default value for the same key. Responses are
+// decoded from gzip or deflate whatever Accept-Encoding is sent. Other encodings return ErrUnsupportedEncoding.
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.headers.Set(key, value)
+	}
+}
+
+// WithTimeout sets the overall timeout for a single request to SAFER. The timeout is applied to a copy of the
+// http.Client, so a client passed to WithHTTPClient is never modified.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		baseURL:     defaultBaseURL,
+		headers:     defaultHeaders(),
+		retryPolicy: DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.httpClient == nil {
+		o.httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if o.timeout > 0 {
+		httpClient := *o.httpClient
+		httpClient.Timeout = o.timeout
+		o.httpClient = &httpClient
+	}
+	return o
+}
@@ -0,0 +1,57 @@
+package safer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	var gotPath, gotUserAgent, gotHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUserAgent = r.Header.Get("User-Agent")
+		gotHeader = r.Header.Get("X-Contact")
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(readTestData("./testdata/snapshot-basic.html"))
+	}))
+	defer ts.Close()
+
+	c := NewClient(
+		WithBaseURL(ts.URL+"/"),
+		WithUserAgent("carrier-vetting/1.0"),
+		WithHeader("X-Contact", "ops@example.com"),
+	)
+	if _, err := c.GetCompanyByDOTNumber("264184"); err != nil {
+		t.Errorf("GetCompanyByDOTNumber should return no error, but got %v", err)
+	}
+	if gotPath != companySnapshotPath {
+		t.Errorf("request path = %v, want %v", gotPath, companySnapshotPath)
+	}
+	if gotUserAgent != "carrier-vetting/1.0" {
+		t.Errorf("User-Agent = %v, want %v", gotUserAgent, "carrier-vetting/1.0")
+	}
+	if gotHeader != "ops@example.com" {
+		t.Errorf("X-Contact = %v, want %v", gotHeader, "ops@example.com")
+	}
+	if defaultHeaders().Get("X-Contact") != "" {
+		t.Errorf("WithHeader should not modify the package default headers")
+	}
+}
+
+func TestNewClient_Timeout(t *testing.T) {
+	c := NewClient()
+	if c.httpClient == nil || c.httpClient.Timeout != defaultTimeout {
+		t.Errorf("default client timeout should be %v", defaultTimeout)
+	}
+
+	httpClient := &http.Client{}
+	c = NewClient(WithTimeout(time.Second), WithHTTPClient(httpClient))
+	if c.httpClient.Timeout != time.Second {
+		t.Errorf("client timeout = %v, want %v", c.httpClient.Timeout, time.Second)
+	}
+	if httpClient.Timeout != 0 {
+		t.Errorf("WithTimeout should not modify the http.Client passed to WithHTTPClient")
+	}
+}
@@ -10,17 +10,51 @@ import (
 var (
 	dotSearchParamsRegex   = regexp.MustCompile(`query_string=([0-9]+)`)
 	mcs150MileageYearRegex = regexp.MustCompile(`([0-9,]+) \(([0-9]{4})\)`)
+	cityStatePostalRegex   = regexp.MustCompile(`^(.+?),\s*([A-Za-z]{2})\s+([A-Za-z0-9][A-Za-z0-9 -]*)$`)
+	usZIPRegex             = regexp.MustCompile(`^([0-9]{5})(?:-?([0-9]{4}))?$`)
+	canadaPostalRegex      = regexp.MustCompile(`^([A-Z][0-9][A-Z]) ?([0-9][A-Z][0-9])$`)
 )
 
+// country names that may follow the city line of an address, mapped to their ISO 3166 code
+var addressCountries = map[string]string{
+	"US": "US", "USA": "US", "UNITED STATES": "US",
+	"CA": "CA", "CAN": "CA", "CANADA": "CA",
+	"MX": "MX", "MEX": "MX", "MEXICO": "MX",
+}
+
+var usStates = map[string]bool{
+	"AL": true, "AK": true, "AZ": true, "AR": true, "CA": true, "CO": true, "CT": true, "DE": true, "DC": true,
+	"FL": true, "GA": true, "HI": true, "ID": true, "IL": true, "IN": true, "IA": true, "KS": true, "KY": true,
+	"LA": true, "ME": true, "MD": true, "MA": true, "MI": true, "MN": true, "MS": true, "MO": true, "MT": true,
+	"NE": true, "NV": true, "NH": true, "NJ": true, "NM": true, "NY": true, "NC": true, "ND": true, "OH": true,
+	"OK": true, "OR": true, "PA": true, "RI": true, "SC": true, "SD": true, "TN": true, "TX": true, "UT": true,
+	"VT": true, "VA": true, "WA": true, "WV": true, "WI": true, "WY": true, "PR": true, "GU": true, "VI": true,
+	"AS": true, "MP": true,
+}
+
+// mexican state abbreviations used by SAFER. Codes shared with a US state are resolved as US.
+var mexicoStates = map[string]bool{
+	"AG": true, "BC": true, "BS": true, "CM": true, "CS": true, "CH": true, "CI": true, "CL": true, "CU": true,
+	"DF": true, "DG": true, "EM": true, "GJ": true, "GR": true, "GT": true, "HG": true, "JA": true, "MC": true,
+	"MR": true, "NA": true, "NL": true, "OA": true, "PU": true, "QE": true, "QR": true, "QT": true, "SI": true,
+	"SL": true, "SO": true, "TB": true, "TL": true, "TM": true, "VE": true, "YU": true, "ZA": true,
+}
+
 func parseInt(text string) int {
+	parsed, _ := parseIntOK(text)
+	return parsed
+}
+
+// parseIntOK is parseInt that also reports whether text held a valid number
+func parseIntOK(text string) (int, bool) {
 	if text == "" {
-		return 0
+		return 0, false
 	}
 	text = strings.Replace(text, ",", "", -1)
 	if parsed, err := strconv.Atoi(text); err == nil {
-		return parsed
+		return parsed, true
 	}
-	return 0
+	return 0, false
 }
 
 func parseDate(text string) *time.Time {
@@ -34,16 +68,22 @@ func parseDate(text string) *time.Time {
 }
 
 func parsePctToFloat32(text string) float32 {
+	parsed, _ := parsePctToFloat32OK(text)
+	return parsed
+}
+
+// parsePctToFloat32OK is parsePctToFloat32 that also reports whether text held a valid percentage
+func parsePctToFloat32OK(text string) (float32, bool) {
 	if text == "" {
-		return 0
+		return 0, false
 	}
 	if text[len(text)-1] == '%' {
 		text = text[:len(text)-1]
 	}
 	if f, err := strconv.ParseFloat(text, 64); err == nil {
-		return float32(f / 100)
+		return float32(f / 100), true
 	}
-	return 0
+	return 0, false
 }
 
 func parseMCS150MileageYear(text string) (mileage int, year string) {
@@ -60,19 +100,67 @@ func parseMCS150MileageYear(text string) (mileage int, year string) {
 // parse an address returned by xpath query on html.
 // multiline address returns an array of strings in this format:
 //	[]string{"3101 S PACKERLAND DR", "GREEN BAY, WI \u00a0 54313", "X"}
-func parseAddress(texts ...string) string {
-	var b strings.Builder
-	var written int
+// the last line holds the city, state or province, and postal code. US, Canadian, and Mexican postal codes are
+// recognized. If the last line can't be split, all lines are kept in Street.
+func parseAddress(texts ...string) Address {
+	var addr Address
 	for _, text := range texts {
-		if text != "X" {
-			if written > 0 {
-				b.WriteString(" ")
+		if text != "X" && text != "" {
+			addr.Raw = append(addr.Raw, text)
+		}
+	}
+	lines := addr.Raw
+	if n := len(lines); n > 1 {
+		if country, ok := addressCountries[strings.ToUpper(normalizeSpace(lines[n-1]))]; ok {
+			addr.Country = country
+			lines = lines[:n-1]
+		}
+	}
+	if len(lines) == 0 {
+		return addr
+	}
+	res := cityStatePostalRegex.FindStringSubmatch(normalizeSpace(lines[len(lines)-1]))
+	if len(res) != 4 {
+		addr.Street = joinAddressLines(lines)
+		return addr
+	}
+	addr.Street = joinAddressLines(lines[:len(lines)-1])
+	addr.City, addr.State = res[1], res[2]
+	postal := strings.ToUpper(res[3])
+	switch {
+	case canadaPostalRegex.MatchString(postal):
+		m := canadaPostalRegex.FindStringSubmatch(postal)
+		addr.ZIP = m[1] + " " + m[2]
+		if addr.Country == "" {
+			addr.Country = "CA"
+		}
+	case usZIPRegex.MatchString(postal):
+		m := usZIPRegex.FindStringSubmatch(postal)
+		addr.ZIP, addr.ZIP4 = m[1], m[2]
+		if addr.Country == "" {
+			if usStates[addr.State] {
+				addr.Country = "US"
+			} else if mexicoStates[addr.State] {
+				addr.Country = "MX"
 			}
-			b.WriteString(strings.ReplaceAll(text, "\u00a0 ", "")) // remove &nbsp;
-			written++
 		}
+	default:
+		addr.ZIP = postal
 	}
-	return b.String()
+	return addr
+}
+
+func joinAddressLines(lines []string) string {
+	cleaned := make([]string, len(lines))
+	for i, line := range lines {
+		cleaned[i] = normalizeSpace(line)
+	}
+	return strings.Join(cleaned, " ")
+}
+
+// normalizeSpace replaces &nbsp; with spaces and collapses runs of whitespace
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(text, "\u00a0", " ")), " ")
 }
 
 func parseDotFromSearchParams(params string) string {
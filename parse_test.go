@@ -173,18 +173,84 @@ func Test_parseAddress(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want string
+		want Address
 	}{
 		{
 			name: "expected",
 			args: args{[]string{"3101 S PACKERLAND DR", "GREEN BAY, WI \u00a0 54313", "X"}},
+			want: Address{Street: "3101 S PACKERLAND DR", City: "GREEN BAY", State: "WI", ZIP: "54313", Country: "US", Raw: []string{"3101 S PACKERLAND DR", "GREEN BAY, WI \u00a0 54313"}},
+		},
+		{
+			name: "zip+4",
+			args: args{[]string{"PO BOX 2545", "GREEN BAY, WI \u00a0 54306-2545"}},
+			want: Address{Street: "PO BOX 2545", City: "GREEN BAY", State: "WI", ZIP: "54306", ZIP4: "2545", Country: "US", Raw: []string{"PO BOX 2545", "GREEN BAY, WI \u00a0 54306-2545"}},
+		},
+		{
+			name: "multiline street",
+			args: args{[]string{"100 MAIN ST", "SUITE 200", "AUSTIN, TX \u00a0 78701"}},
+			want: Address{Street: "100 MAIN ST SUITE 200", City: "AUSTIN", State: "TX", ZIP: "78701", Country: "US", Raw: []string{"100 MAIN ST", "SUITE 200", "AUSTIN, TX \u00a0 78701"}},
+		},
+		{
+			name: "canada",
+			args: args{[]string{"6711 MISSISSAUGA RD", "MISSISSAUGA, ON \u00a0 L5N 2W3"}},
+			want: Address{Street: "6711 MISSISSAUGA RD", City: "MISSISSAUGA", State: "ON", ZIP: "L5N 2W3", Country: "CA", Raw: []string{"6711 MISSISSAUGA RD", "MISSISSAUGA, ON \u00a0 L5N 2W3"}},
+		},
+		{
+			name: "canada without space",
+			args: args{[]string{"1 RUE PRINCIPALE", "MONTREAL, QC \u00a0 H2X1Y4"}},
+			want: Address{Street: "1 RUE PRINCIPALE", City: "MONTREAL", State: "QC", ZIP: "H2X 1Y4", Country: "CA", Raw: []string{"1 RUE PRINCIPALE", "MONTREAL, QC \u00a0 H2X1Y4"}},
+		},
+		{
+			name: "mexico",
+			args: args{[]string{"BLVD DIAZ ORDAZ 1500", "TIJUANA, BC \u00a0 22000"}},
+			want: Address{Street: "BLVD DIAZ ORDAZ 1500", City: "TIJUANA", State: "BC", ZIP: "22000", Country: "MX", Raw: []string{"BLVD DIAZ ORDAZ 1500", "TIJUANA, BC \u00a0 22000"}},
+		},
+		{
+			name: "trailing country",
+			args: args{[]string{"AV CONSTITUCION 400", "MONTERREY, NL \u00a0 64000", "MEXICO"}},
+			want: Address{Street: "AV CONSTITUCION 400", City: "MONTERREY", State: "NL", ZIP: "64000", Country: "MX", Raw: []string{"AV CONSTITUCION 400", "MONTERREY, NL \u00a0 64000", "MEXICO"}},
+		},
+		{
+			name: "unparseable",
+			args: args{[]string{"SOMEWHERE OUT WEST"}},
+			want: Address{Street: "SOMEWHERE OUT WEST", Raw: []string{"SOMEWHERE OUT WEST"}},
+		},
+		{
+			name: "empty",
+			args: args{[]string{}},
+			want: Address{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAddress(tt.args.texts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAddress() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddress_String(t *testing.T) {
+	tests := []struct {
+		name string
+		addr Address
+		want string
+	}{
+		{
+			name: "expected",
+			addr: parseAddress("3101 S PACKERLAND DR", "GREEN BAY, WI \u00a0 54313", "X"),
 			want: "3101 S PACKERLAND DR GREEN BAY, WI 54313",
 		},
+		{
+			name: "empty",
+			addr: Address{},
+			want: "",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := parseAddress(tt.args.texts...); got != tt.want {
-				t.Errorf("parseAddress() = %v, want %v", got, tt.want)
+			if got := tt.addr.String(); got != tt.want {
+				t.Errorf("Address.String() = %v, want %v", got, tt.want)
 			}
 		})
 	}
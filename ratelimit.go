@@ -0,0 +1,117 @@
+package safer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitState is a snapshot of a Client's rate limiter. A zero Limit means requests are not rate limited.
+type RateLimitState struct {
+	// Limit is the sustained number of requests allowed per second
+	Limit float64 `json:"limit"`
+	// Burst is the maximum number of requests allowed at once
+	Burst int `json:"burst"`
+	// Tokens is the number of requests that can be sent right now without waiting. Negative when callers
+	// are already waiting on the limiter.
+	Tokens float64 `json:"tokens"`
+}
+
+// WithRateLimit limits the client to requestsPerSecond requests, allowing bursts of up to burst requests. The
+// limit is shared by all snapshot and search calls on the client, including retries, so a single Client shared
+// across goroutines enforces one global budget. Calls wait for their turn until their context is done.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(o *options) {
+		o.rateLimiter = newRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// RateLimit returns the current state of the client's rate limiter
+func (c *Client) RateLimit() RateLimitState {
+	return c.scraper.rateLimiter.state()
+}
+
+// rateLimiter is a token bucket refilled at limit tokens per second up to burst tokens
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(limit float64, burst int) *rateLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limit:  limit,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is done. A token is reserved up front so concurrent callers
+// are served in order, and given back if the caller stops waiting.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	l.advance(time.Now())
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.limit * float64(time.Second))
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		l.cancel()
+		return context.DeadlineExceeded
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
+
+// cancel returns a reserved token to the bucket
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	l.advance(time.Now())
+	l.tokens++
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.mu.Unlock()
+}
+
+// advance refills the bucket for the time elapsed since the last call. Must be called with mu held.
+func (l *rateLimiter) advance(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.limit
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+		l.last = now
+	}
+}
+
+func (l *rateLimiter) state() RateLimitState {
+	if l == nil {
+		return RateLimitState{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(time.Now())
+	return RateLimitState{
+		Limit:  l.limit,
+		Burst:  l.burst,
+		Tokens: l.tokens,
+	}
+}
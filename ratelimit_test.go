@@ -0,0 +1,74 @@
+package safer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Burst(t *testing.T) {
+	l := newRateLimiter(10, 3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		// a whole token in the bucket means wait takes it without sleeping
+		if state := l.state(); state.Tokens < 1 {
+			t.Fatalf("tokens before request %d = %v, want a token left in the burst", i+1, state.Tokens)
+		}
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("wait should return no error, but got %v", err)
+		}
+	}
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("wait should return no error, but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("request after burst took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestRateLimiter_SharedAcrossGoroutines(t *testing.T) {
+	l := newRateLimiter(100, 1)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.wait(context.Background()); err != nil {
+				t.Errorf("wait should return no error, but got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("10 requests at 100/s took %v, want at least 90ms", elapsed)
+	}
+}
+
+func TestRateLimiter_ContextDeadline(t *testing.T) {
+	l := newRateLimiter(1, 1)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("wait should return no error, but got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait should return context.DeadlineExceeded but got %v", err)
+	}
+	if state := l.state(); state.Tokens < -0.1 {
+		t.Errorf("tokens = %v, the reservation should have been returned", state.Tokens)
+	}
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	if state := NewClient().RateLimit(); state != (RateLimitState{}) {
+		t.Errorf("RateLimit() = %+v, want zero state without a limiter", state)
+	}
+	c := NewClient(WithRateLimit(2, 5))
+	state := c.RateLimit()
+	if state.Limit != 2 || state.Burst != 5 || state.Tokens != 5 {
+		t.Errorf("RateLimit() = %+v, want limit 2, burst 5 and a full bucket", state)
+	}
+}
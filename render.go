@@ -0,0 +1,594 @@
+package safer
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RenderCompanySnapshot writes snapshot as a SAFER company snapshot page, with the structure ParseCompanySnapshot
+// and DefaultMapping read. Use it to build test pages for carriers the captured pages don't cover. Parsing the
+// page returns the snapshot, with checked boxes listed in SAFER's order and DocketNumbers taken from
+// MCMXFFNumbers. Vocabulary fields are written from their ...Raw text when it's set, and addresses from their Raw
+// lines.
+func RenderCompanySnapshot(w io.Writer, snapshot *CompanySnapshot) error {
+	return snapshotTemplate.Execute(w, newSnapshotPage(snapshot))
+}
+
+// RenderSearchResults writes results as a SAFER company name search results page, with the structure
+// ParseSearchResults and SearchResultScanner read
+func RenderSearchResults(w io.Writer, results []CompanyResult) error {
+	return searchTemplate.Execute(w, results)
+}
+
+// checkbox grids of the snapshot page, by column, with the labels SAFER shows
+var (
+	operationClassificationGrid = [][]string{
+		{"Auth. For Hire", "Exempt For Hire", "Private(Property)", "Priv. Pass. (Business)"},
+		{"Priv. Pass.(Non-business)", "Migrant", "U.S. Mail", "Fed. Gov't"},
+		{"State Gov't", "Local Gov't", "Indian Nation"},
+	}
+	carrierOperationGrid = [][]string{
+		{"Interstate"},
+		{"Intrastate Only (HM)"},
+		{"Intrastate Only (Non-HM)"},
+	}
+	cargoCarriedGrid = [][]string{
+		{"General Freight", "Household Goods", "Metal: sheets, coils, rolls", "Motor Vehicles", "Drive/Tow away",
+			"Logs, Poles, Beams, Lumber", "Building Materials", "Mobile Homes", "Machinery, Large Objects", "Fresh Produce"},
+		{"Liquids/Gases", "Intermodal Cont.", "Passengers", "Oilfield Equipment", "Livestock", "Grain, Feed, Hay",
+			"Coal/Coke", "Meat", "Garbage/Refuse", "US Mail"},
+		{"Chemicals", "Commodities Dry Bulk", "Refrigerated Food", "Beverages", "Paper Products", "Utilities",
+			"Agricultural/Farm Supplies", "Construction", "Water Well"},
+	}
+)
+
+// page text written for vocabulary codes without their ...Raw text
+var (
+	entityTypeLabels = map[EntityType]string{
+		EntityTypeCarrier:                     "CARRIER",
+		EntityTypeBroker:                      "BROKER",
+		EntityTypeShipper:                     "SHIPPER",
+		EntityTypeFreightForwarder:            "FREIGHT FORWARDER",
+		EntityTypeCargoTank:                   "CARGO TANK",
+		EntityTypeIntermodalEquipmentProvider: "IEP",
+		EntityTypeRegistrant:                  "REGISTRANT",
+	}
+	operatingStatusLabels = map[OperatingStatus]string{
+		OperatingStatusActive:        "ACTIVE",
+		OperatingStatusAuthorized:    "AUTHORIZED",
+		OperatingStatusNotAuthorized: "NOT AUTHORIZED",
+		OperatingStatusOutOfService:  "OUT-OF-SERVICE",
+		OperatingStatusInactive:      "INACTIVE",
+	}
+	ratingLabels = map[Rating]string{
+		RatingSatisfactory:   "Satisfactory",
+		RatingConditional:    "Conditional",
+		RatingUnsatisfactory: "Unsatisfactory",
+		RatingNotRated:       "None",
+	}
+)
+
+// snapshotPage holds the text of each part of a rendered snapshot page
+type snapshotPage struct {
+	LatestUpdate            string
+	EntityType              string
+	OperatingStatus         string
+	OutOfService            bool
+	OutOfServiceDate        string
+	LegalName               string
+	DBAName                 string
+	PhysicalAddress         []string
+	Phone                   string
+	MailingAddress          []string
+	DOTNumber               string
+	StateCarrierID          string
+	MCMXFFNumbers           []string
+	DUNSNumber              string
+	PowerUnits              string
+	Drivers                 string
+	MCS150FormDate          string
+	MCS150Mileage           string
+	OperationClassification checkboxGrid
+	CarrierOperation        checkboxGrid
+	CargoCarried            checkboxGrid
+	USInspections           inspectionTable
+	CanadaInspections       inspectionTable
+	USCrashes               CrashSummary
+	CanadaCrashes           CrashSummary
+	Safety                  *safetyTable
+}
+
+// checkboxGrid is a grid of labels, by column, with those checked marked by an X
+type checkboxGrid struct {
+	Label   string
+	Columns [][]checkbox
+}
+
+type checkbox struct {
+	Label   string
+	Checked bool
+	// Extra is a label SAFER doesn't list, shown in a queryfield cell at the end of the grid
+	Extra bool
+}
+
+type inspectionTable struct {
+	Columns         []inspectionColumn
+	NationalAverage bool
+}
+
+type inspectionColumn struct {
+	Type            string
+	Inspections     int
+	OutOfService    int
+	OutOfServicePct string
+	NationalAverage string
+}
+
+type safetyTable struct {
+	RatingDate string
+	ReviewDate string
+	Rating     string
+	Type       string
+}
+
+func newSnapshotPage(s *CompanySnapshot) *snapshotPage {
+	page := &snapshotPage{
+		LatestUpdate:     formatDate(s.LatestUpdateDate),
+		EntityType:       entityTypeText(s.EntityType, s.EntityTypeOther),
+		OperatingStatus:  s.OperatingStatusRaw,
+		OutOfService:     s.OperatingStatus == OperatingStatusOutOfService,
+		OutOfServiceDate: formatDate(s.OutOfServiceDate),
+		LegalName:        s.LegalName,
+		DBAName:          s.DBAName,
+		PhysicalAddress:  addressLines(s.PhysicalAddress),
+		Phone:            s.Phone,
+		MailingAddress:   addressLines(s.MailingAddress),
+		DOTNumber:        s.DOTNumber,
+		StateCarrierID:   s.StateCarrierID,
+		MCMXFFNumbers:    s.MCMXFFNumbers,
+		DUNSNumber:       s.DUNSNumber,
+		PowerUnits:       formatThousands(s.PowerUnits),
+		Drivers:          formatThousands(s.Drivers),
+		MCS150FormDate:   formatDate(s.MCS150FormDate),
+		USCrashes:        s.USCrashes,
+		CanadaCrashes:    s.CanadaCrashes,
+		USInspections: inspectionTable{
+			Columns: []inspectionColumn{
+				newInspectionColumn("Vehicle", s.USVehicleInspections),
+				newInspectionColumn("Driver", s.USDriverInspections),
+				newInspectionColumn("Hazmat", s.USHazmatInspections),
+				newInspectionColumn("IEP", s.USIEPInspections),
+			},
+			NationalAverage: true,
+		},
+		CanadaInspections: inspectionTable{
+			Columns: []inspectionColumn{
+				newInspectionColumn("Vehicle", s.CanadaVehicleInspections),
+				newInspectionColumn("Driver", s.CanadaDriverInspections),
+			},
+		},
+	}
+	if page.OperatingStatus == "" {
+		page.OperatingStatus = operatingStatusLabels[s.OperatingStatus]
+	}
+	if page.OutOfServiceDate == "" {
+		page.OutOfServiceDate = "None"
+	}
+	if page.DUNSNumber == "" {
+		page.DUNSNumber = "--"
+	}
+	if s.MCS150Year != "" {
+		page.MCS150Mileage = formatThousands(s.MCS150Mileage) + " (" + s.MCS150Year + ")"
+	}
+
+	classifications := make(map[string]bool)
+	for _, c := range s.OperationClassification {
+		classifications[string(c)] = true
+	}
+	page.OperationClassification = newCheckboxGrid("Operation Classification", operationClassificationGrid, func(label string) bool {
+		return classifications[string(operationClassifications[vocabularyKey(label)])]
+	}, s.OperationClassificationOther, true)
+
+	operations := make(map[string]bool)
+	for _, op := range s.CarrierOperation {
+		operations[string(op)] = true
+	}
+	page.CarrierOperation = newCheckboxGrid("Carrier Operation", carrierOperationGrid, func(label string) bool {
+		return operations[string(carrierOperations[vocabularyKey(label)])]
+	}, s.CarrierOperationOther, false)
+
+	cargo := make(map[string]bool)
+	for _, c := range s.CargoCarried {
+		cargo[string(c)] = true
+	}
+	page.CargoCarried = newCheckboxGrid("Cargo Carried", cargoCarriedGrid, func(label string) bool {
+		return cargo[string(cargoTypes[vocabularyKey(label)])]
+	}, s.CargoCarriedOther, true)
+
+	if safety := s.Safety; safety.RatingDate != nil || safety.ReviewDate != nil || safety.Rating != "" ||
+		safety.RatingRaw != "" || safety.Type != "" {
+		page.Safety = &safetyTable{
+			RatingDate: formatDate(safety.RatingDate),
+			ReviewDate: formatDate(safety.ReviewDate),
+			Rating:     safety.RatingRaw,
+			Type:       safety.Type,
+		}
+		if page.Safety.Rating == "" {
+			page.Safety.Rating = ratingLabels[safety.Rating]
+		}
+		if page.Safety.RatingDate == "" {
+			page.Safety.RatingDate = "None"
+		}
+		if page.Safety.ReviewDate == "" {
+			page.Safety.ReviewDate = "None"
+		}
+	}
+	return page
+}
+
+// newCheckboxGrid lays out a checkbox grid, checking the labels for which checked returns true. Labels SAFER
+// doesn't list are added to the end of the last column, after an empty extra row if the grid has one.
+func newCheckboxGrid(label string, grid [][]string, checked func(label string) bool, extras []string, extraRow bool) checkboxGrid {
+	columns := make([][]checkbox, len(grid))
+	for i, labels := range grid {
+		for _, l := range labels {
+			columns[i] = append(columns[i], checkbox{Label: l, Checked: checked(l)})
+		}
+	}
+	last := len(columns) - 1
+	for _, extra := range extras {
+		columns[last] = append(columns[last], checkbox{Label: extra, Checked: true, Extra: true})
+	}
+	if len(extras) == 0 && extraRow {
+		columns[last] = append(columns[last], checkbox{Extra: true})
+	}
+	return checkboxGrid{Label: label, Columns: columns}
+}
+
+func newInspectionColumn(inspectionType string, summary InspectionSummary) inspectionColumn {
+	return inspectionColumn{
+		Type:            inspectionType,
+		Inspections:     summary.Inspections,
+		OutOfService:    summary.OutOfService,
+		OutOfServicePct: formatPct(summary.OutOfServicePct),
+		NationalAverage: formatPct(summary.NationalAverage),
+	}
+}
+
+// entityTypeText joins entity types the way SAFER shows them, e.g. "CARRIER/CARGO TANK/BROKER"
+func entityTypeText(known []EntityType, other []string) string {
+	parts := make([]string, 0, len(known)+len(other))
+	for _, entityType := range known {
+		parts = append(parts, entityTypeLabels[entityType])
+	}
+	return strings.Join(append(parts, other...), "/")
+}
+
+// addressLines returns the lines of an address, built from its parts when it has no Raw lines
+func addressLines(a Address) []string {
+	if len(a.Raw) > 0 || a.City == "" {
+		return a.Raw
+	}
+	postal := a.ZIP
+	if a.ZIP4 != "" {
+		postal += "-" + a.ZIP4
+	}
+	var lines []string
+	if a.Street != "" {
+		lines = append(lines, a.Street)
+	}
+	return append(lines, a.City+", "+a.State+"   "+postal)
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("01/02/2006")
+}
+
+// formatPct formats a fraction as SAFER does, e.g. 0.136 is "13.6%". The decimal point of f's shortest
+// representation is moved rather than multiplying by 100, so parsing the percentage gives back f.
+func formatPct(f float32) string {
+	if f == 0 {
+		return "0%"
+	}
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	mantissa := strings.SplitN(strconv.FormatFloat(float64(f), 'e', -1, 32), "e", 2)
+	digits := strings.Replace(mantissa[0], ".", "", 1)
+	exp, _ := strconv.Atoi(mantissa[1])
+	point := exp + 3 // digits before the decimal point once multiplied by 100
+	switch {
+	case point <= 0:
+		digits = "0." + strings.Repeat("0", -point) + digits
+	case point >= len(digits):
+		digits += strings.Repeat("0", point-len(digits))
+	default:
+		digits = digits[:point] + "." + digits[point:]
+	}
+	return sign + digits + "%"
+}
+
+// formatThousands formats n with thousands separators, e.g. "10,884"
+func formatThousands(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+var snapshotTemplate = template.Must(template.New("snapshot").Parse(`<HTML>
+ <HEAD>
+  <TITLE>SAFER Web - Company Snapshot {{.LegalName}}</TITLE>
+  <LINK rel="stylesheet" href="safer.css" type="text/css">
+ </HEAD>
+<BODY>
+ <P>&nbsp;
+   <TABLE border="0" cellpadding="0" cellspacing="0" WIDTH="100%" ALIGN=CENTER summary="Table used for formatting purposes only">
+     <TR>
+       <TH SCOPE="COL"><div class="hidden">SAFER Layout</div></TH>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW"><div class="hidden">SAFER Table Layout</div></TH>
+       <TD>
+         <TABLE border="0" cellpadding="0" cellspacing="0" WIDTH="100%" summary="For formatting purpose">
+           <TR><TH SCOPE="COL"><div class="hidden">SAFER Layout</div></TH></TR>
+           <TR><TD ALIGN=CENTER>
+               <TABLE BORDER=0 summary="Table used for formatting purposes only">
+                 <TR>
+                   <TH SCOPE="COL"><div class="hidden">SAFER Layout</div></TH>
+                 </TR>
+                 <TR>
+                   <TD>
+                     <P align="right">
+                        <FONT size="5" face="arial" color="#2040a0"><B><I>Company Snapshot</I></B><br></FONT>
+                        <FONT size="3" face="arial"><B>{{.LegalName}}</B><br>USDOT Number: {{.DOTNumber}}<br></FONT>
+                     </P>
+                   </TD></TR>
+                 <TR>
+                   <TD COLSPAN=2>
+                     <h4><FONT size="3" face="arial"><B>
+                           <A name="ID">ID/Operations</A> |
+                           <A href="#Inspections">Inspections/Crashes In US</A> |
+                           <A href="#CaInspections">Inspections/Crashes In Canada</A> |
+                           <A href="#Safety">Safety Rating</A><br>
+                         </B></FONT></h4>
+                     <FONT size="2" face="arial">
+                       <B>Carriers:</B> If you would like to update the following ID/Operations information, please
+                       complete and submit form MCS-150.
+                       <br><br><br>
+                       <B>Carrier and other users:</B> FMCSA provides the Company Safety Profile (CSP) to motor
+                       carriers and the general public.
+                       <br><br>
+                       <B>The information below reflects the content of the FMCSA management information systems as of <FONT color="#0000C0">{{.LatestUpdate}}.</FONT></B><br>
+                     </FONT>
+                   </TD></TR>
+               </TABLE>
+ <CENTER>
+   <TABLE border=1 WIDTH=70% BORDERCOLOR=SILVER cellspacing=0 cellpadding=4 summary="For formatting purpose">
+     <TR><TH SCOPE="COL"><div class="hidden">SAFER Layout</div></TH></TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#EntityType">Entity Type:</A></TH>
+       <TD colspan=3 class="queryfield" valign=top>{{.EntityType}}&nbsp;</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#OOS">Operating Status:</A></TH>
+       {{if .OutOfService}}<TD width=30% class="queryfield"><font color="red" size="6"><b>{{.OperatingStatus}}</b></font></TD>
+       {{else}}<TD width=30% class="queryfield">{{.OperatingStatus}}</TD>
+       {{end}}<TH SCOPE="ROW" class="querylabelbkg" align=right><a class="querylabel" href="saferhelp.aspx#OOSDate">Out of Service Date:</a></TH>
+       <TD width=30% class="queryfield">{{.OutOfServiceDate}}</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#Carrier">Legal Name:</A></TH>
+       <TD class="queryfield" valign=top colspan=3>{{.LegalName}}&nbsp;</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#DBAName">DBA Name:</A></TH>
+       <TD class="queryfield" valign=top colspan=3>{{.DBAName}}&nbsp;</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#PhysicalAddress">Physical Address:</A></TH>
+       <TD class="queryfield" valign=top colspan=3 id="physicaladdressvalue">{{range $i, $line := .PhysicalAddress}}{{if $i}}<br>{{end}}{{$line}}{{end}}&nbsp;</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#Phone">Phone:</A></TH>
+       <TD class="queryfield" valign=top colspan=3>{{.Phone}}&nbsp;</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#MailingAddress">Mailing Address:</A></TH>
+       <TD class="queryfield" valign=top colspan=3 id="mailingaddressvalue">{{range $i, $line := .MailingAddress}}{{if $i}}<br>{{end}}{{$line}}{{end}}&nbsp;</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#USDOTID">USDOT Number:</A></TH>
+       <TD class="queryfield" valign=top>{{.DOTNumber}}&nbsp;</TD>
+       <TH class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#StateID">State Carrier ID Number:</A></TH>
+       <TD class="queryfield" valign=top>{{.StateCarrierID}}&nbsp;</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#ICCNumbers">MC/MX/FF Number(s):</A></TH>
+       <TD class="queryfield" valign=top>{{range .MCMXFFNumbers}}
+         <A href="http://li-public.fmcsa.dot.gov/LIVIEW/pkg_carrquery.prc_carrlist?n_dotno={{$.DOTNumber}}">{{.}}</A><br>{{end}}
+         &nbsp;</TD>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#DUNSNumber">DUNS Number:</A></TH>
+       <TD class="queryfield" valign=top>{{.DUNSNumber}}&nbsp;</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#PowerUnits">Power Units:</A></TH>
+       <TD class="queryfield" valign=top>{{.PowerUnits}}&nbsp;</TD>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#Drivers">Drivers:</A></TH>
+       <TD valign=top><FONT style=font-size:80% face=arial color=#0000C0><B>{{.Drivers}}&nbsp;</B></FONT></TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#">MCS-150 Form Date:</A></TH>
+       <TD class="queryfield" valign=top>{{.MCS150FormDate}}&nbsp;</TD>
+       <TH SCOPE="ROW" class="querylabelbkg" align=right><A class="querylabel" href="saferhelp.aspx#">MCS-150 Mileage (Year):</A></TH>
+       <TD valign=top><FONT style=font-size:80% face=arial color=#0000C0><B>{{.MCS150Mileage}}&nbsp;</B></FONT></TD>
+     </TR>
+     {{template "checkboxes" .OperationClassification}}
+     {{template "checkboxes" .CarrierOperation}}
+     {{template "checkboxes" .CargoCarried}}
+   </TABLE>
+ </CENTER>
+ <br>
+ <CENTER><IMG src="Images/SAFER_hr.jpg" alt="horizontal line" width=90% height=2></CENTER>
+ <br>
+ <H4><FONT size="3" face="arial"><B><A name="Inspections">Inspections/Crashes In US</A></B></FONT></H4>
+ <A class="querylabel" href="saferhelp.aspx#Inspections">Inspections:</A>
+ {{template "inspections" .USInspections}}
+ <A class="querylabel" href="saferhelp.aspx#Accidents">Crashes:</A>
+ {{template "crashes" .USCrashes}}
+ <br>
+ <CENTER><IMG src="Images/SAFER_hr.jpg" alt="horizontal line" width=90% height=2></CENTER>
+ <br>
+ <H4><FONT size="3" face="arial"><B><A name="CAInspections">Inspections/Crashes In Canada</A></B></FONT></H4>
+ <A class="querylabel" href="saferhelp.aspx#InspectionsCA">Inspections:</A>
+ {{template "inspections" .CanadaInspections}}
+ <A class="querylabel" href="saferhelp.aspx#InspectionsCA">Crashes:</A>
+ {{template "crashes" .CanadaCrashes}}
+ <br>
+ <CENTER><IMG src="Images/SAFER_hr.jpg" alt="horizontal line" width=80% height=2></CENTER>
+ <br>
+ <H4><FONT size="3" face="arial"><B><A name="Safety">Safety Rating</A></B></FONT></H4>
+ <FONT size="2" face="arial">
+   <I>The Federal safety rating does not necessarily reflect the safety of the carrier when operating in intrastate
+   commerce.</I>
+ </FONT><br>
+ {{with .Safety}}<CENTER>
+   <TABLE border="1" bordercolor="silver" width=70% cellpadding=3 cellspacing=0 summary="Review Information">
+     <TR>
+       <TH SCOPE="COL"><div class="hidden">Review Information</div></TH>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" width=20% class="querylabelbkg">Rating Date:</TH>
+       <TD width=30% class="queryfield">{{.RatingDate}}</TD>
+       <TH SCOPE="ROW" width=20% class="querylabelbkg">Review Date:</TH>
+       <TD width=30% class="queryfield">{{.ReviewDate}}</TD>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" class="querylabelbkg">Rating:</TH>
+       <TD class="queryfield">{{.Rating}}</TD>
+       <TH SCOPE="ROW" class="querylabelbkg">Type:</TH>
+       <TD class="queryfield">{{.Type}}</TD>
+     </TR>
+   </TABLE>
+ </CENTER>{{end}}
+           </TD></TR>
+         </TABLE>
+       </TD>
+     </TR>
+   </TABLE>
+ </P>
+</BODY>
+</HTML>
+{{define "checkboxes"}}<TR>
+       <TD colspan=4 class="querylabelbkg" valign=top><A class="querylabel">{{.Label}}:</A></TD>
+     </TR>
+     <TR>
+       <TD colspan=4 align=center>
+         <TABLE border=0 cellpadding=2 cellspacing=0 summary="{{.Label}}">
+           <TR><TH SCOPE="COL"><div class="hidden">{{.Label}}</div></TH></TR>
+           <TR>{{range .Columns}}
+             <TD width=32% valign=top>
+               <TABLE border=0 cellpadding=2 cellspacing=0 summary="For formatting purpose">
+                 <TR><TH SCOPE="COL"><div class="hidden">SAFER Layout</div></TH></TR>{{range .}}
+                 <TR>
+                   <TD class="queryfield">{{if .Checked}}X{{end}}</TD>
+                   {{if .Extra}}<TD class="queryfield">{{.Label}}</TD>{{else}}<TD><FONT style=font-size:80% face=arial>{{.Label}}</FONT></TD>{{end}}
+                 </TR>{{end}}
+               </TABLE>
+             </TD>{{end}}
+           </TR>
+         </TABLE>
+       </TD>
+     </TR>{{end}}
+{{define "inspections"}}<CENTER>
+   <TABLE width=70% border=1 bordercolor="silver" cellspacing=0 cellpadding=3 summary="Inspections">
+     <TR>
+       <TH SCOPE="COL" align="right" width=20% class="querylabelbkg">Inspection Type</TH>{{range .Columns}}
+       <TH SCOPE="COL" align="center" width=20% class="querylabelbkg">{{.Type}}</TH>{{end}}
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" align="right" class="querylabelbkg">Inspections</TH>{{range .Columns}}
+       <TD align="center" class="queryfield">{{.Inspections}}</TD>{{end}}
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" align="right" class="querylabelbkg">Out of Service</TH>{{range .Columns}}
+       <TD align="center" class="queryfield">{{.OutOfService}}</TD>{{end}}
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" align="right" class="querylabelbkg">Out of Service %</TH>{{range .Columns}}
+       <TD align="center" class="queryfield">{{.OutOfServicePct}}</TD>{{end}}
+     </TR>{{if .NationalAverage}}
+     <TR>
+       <TH SCOPE="ROW" align="right" class="querylabelbkg">Nat'l Average %</TH>{{range .Columns}}
+       <TD align="center"><FONT style=font-size:80% face=arial>{{.NationalAverage}}</FONT></TD>{{end}}
+     </TR>{{end}}
+   </TABLE>
+ </CENTER>{{end}}
+{{define "crashes"}}<CENTER>
+   <TABLE width=70% border=1 bordercolor="silver" cellspacing=0 cellpadding=3 summary="Crashes">
+     <TR>
+       <TH SCOPE="COL" align="right" width=20% class="querylabelbkg">Type</TH>
+       <TH SCOPE="COL" align="center" width=20% class="querylabelbkg">Fatal</TH>
+       <TH SCOPE="COL" align="center" width=20% class="querylabelbkg">Injury</TH>
+       <TH SCOPE="COL" align="center" width=20% class="querylabelbkg">Tow</TH>
+       <TH SCOPE="COL" align="center" width=20% class="querylabelbkg">Total</TH>
+     </TR>
+     <TR>
+       <TH SCOPE="ROW" align="right" class="querylabelbkg">Crashes</TH>
+       <TD align="center" class="queryfield">{{.Fatal}}</TD>
+       <TD align="center" class="queryfield">{{.Injury}}</TD>
+       <TD align="center" class="queryfield">{{.Tow}}</TD>
+       <TD align="center" class="queryfield">{{.Total}}</TD>
+     </TR>
+   </TABLE>
+ </CENTER>{{end}}`))
+
+var searchTemplate = template.Must(template.New("search").Parse(`<HTML>
+<HEAD>
+    <TITLE>SAFER WEB - Select Company</TITLE>
+    <LINK title="Style Sheet" href="safer.css" rel=stylesheet>
+</HEAD>
+<BODY>
+<TABLE cellSpacing=0 cellPadding=1 width="100%" border=0 summary="Table used for formatting purposes only">
+    <TR>
+        <TH SCOPE="ROW"><div class="hidden">SAFER Table Layout</div></TH>
+        <TD vAlign=centered><IMG alt="FMCSA Logo" SRC="images/SAFER-System.GIF" width="100%" border=0></TD>
+    </TR>
+</TABLE>
+<TABLE BORDER=0 ALIGN=CENTER summary="For formatting purpose">
+    <TR>
+        <TH SCOPE="COL"><div class="hidden">Search Information</div></TH>
+    </TR>
+    <TR>
+        <TD><FONT size="4" face="arial" color="#2040a0"><B><I>Possible Keyword Matches &nbsp;</I></B></FONT></TD>
+    </TR>
+</TABLE>
+<TABLE BORDER=0 summary="Table used for formatting purposes only">
+    <TR>
+        <TH SCOPE="COL" width=30% ALIGN="center"><B><i>CARRIER/DBA NAME</i></B></TH>
+        <TH SCOPE="COL" width=20% ALIGN="center"><B><i>LOCATION</I></B></TH>
+    </TR>{{range .}}
+    <tr>
+        <th scope="rpw" width=30% align="center"><b><a href="query.asp?searchtype=ANY&query_type=queryCarrierSnapshot&query_param=USDOT&original_query_param=NAME&query_string={{.DOTNumber}}&original_query_string={{.Name}}">{{.Name}}</a></b></th>
+        <td width=20% align="center"><b>{{.Location}}</b></td>
+    </tr>{{end}}
+</TABLE>
+</BODY>
+</HTML>
+`))
@@ -0,0 +1,310 @@
+package safer
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func TestRenderCompanySnapshot_RoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		want := randomSnapshot(rnd)
+		var buf bytes.Buffer
+		if err := RenderCompanySnapshot(&buf, want); err != nil {
+			t.Fatalf("RenderCompanySnapshot() error = %v", err)
+		}
+		root, err := htmlquery.Parse(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("htmlquery.Parse() error = %v", err)
+		}
+		got, err := htmlNodeToCompanySnapshot(root)
+		if err != nil {
+			t.Fatalf("htmlNodeToCompanySnapshot() error = %v, for %+v", err, want)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("htmlNodeToCompanySnapshot() = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestRenderCompanySnapshot_Fixtures(t *testing.T) {
+	for _, name := range []string{"snapshot-basic", "snapshot-extras", "snapshot-oos"} {
+		want, err := ParseCompanySnapshot(bytes.NewReader(readTestData("./testdata/" + name + ".html")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := RenderCompanySnapshot(&buf, want); err != nil {
+			t.Fatalf("%s: RenderCompanySnapshot() error = %v", name, err)
+		}
+		got, report, err := ParseCompanySnapshotWithReport(&buf, ParseOptions{Strict: true})
+		if err != nil {
+			t.Fatalf("%s: ParseCompanySnapshotWithReport() error = %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: ParseCompanySnapshotWithReport() = %+v, want %+v", name, got, want)
+		}
+		if len(report.Warnings) != 0 || len(report.UnknownLabels) != 0 || len(report.MissingSections) != 0 {
+			t.Errorf("%s: ParseCompanySnapshotWithReport() report = %+v, want no warnings", name, report)
+		}
+	}
+}
+
+func TestRenderSearchResults_RoundTrip(t *testing.T) {
+	tests := [][]CompanyResult{
+		{},
+		{{Name: "SCHNEIDER NATIONAL CARRIERS INC", DOTNumber: "264184", Location: "GREEN BAY, WI"}},
+		{
+			{Name: "A & B TRUCKING", DOTNumber: "1", Location: "O'FALLON, MO"},
+			{Name: "<ACME> LOGISTICS", DOTNumber: "3878512", Location: "LAREDO, TX"},
+		},
+	}
+	for _, want := range tests {
+		var buf bytes.Buffer
+		if err := RenderSearchResults(&buf, want); err != nil {
+			t.Fatalf("RenderSearchResults() error = %v", err)
+		}
+		got, err := ParseSearchResults(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("ParseSearchResults() error = %v", err)
+		}
+		if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+			t.Errorf("ParseSearchResults() = %v, want %v", got, want)
+		}
+		scanner := NewSearchResultScanner(bytes.NewReader(buf.Bytes()))
+		var scanned []CompanyResult
+		for scanner.Scan() {
+			scanned = append(scanned, scanner.Result())
+		}
+		if err := scanner.Err(); err != nil || len(scanned) != len(want) || (len(want) > 0 && !reflect.DeepEqual(scanned, want)) {
+			t.Errorf("SearchResultScanner = %v, %v, want %v", scanned, err, want)
+		}
+	}
+}
+
+func Test_formatPct(t *testing.T) {
+	tests := []struct {
+		f    float32
+		want string
+	}{
+		{0, "0%"},
+		{0.136, "13.6%"},
+		{0.014, "1.4%"},
+		{0.2084, "20.84%"},
+		{0.0005, "0.05%"},
+		{1, "100%"},
+		{12.5, "1250%"},
+	}
+	for _, tt := range tests {
+		if got := formatPct(tt.f); got != tt.want {
+			t.Errorf("formatPct(%v) = %v, want %v", tt.f, got, tt.want)
+		}
+		if got := parsePctToFloat32(formatPct(tt.f)); got != tt.f {
+			t.Errorf("parsePctToFloat32(formatPct(%v)) = %v", tt.f, got)
+		}
+	}
+}
+
+func Test_formatThousands(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{10884, "10,884"},
+		{1100158928, "1,100,158,928"},
+		{-1234, "-1,234"},
+	}
+	for _, tt := range tests {
+		if got := formatThousands(tt.n); got != tt.want {
+			t.Errorf("formatThousands(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+// randomSnapshot returns a snapshot as htmlNodeToCompanySnapshot would parse it from a SAFER page
+func randomSnapshot(rnd *rand.Rand) *CompanySnapshot {
+	s := &CompanySnapshot{
+		LatestUpdateDate:         randomDate(rnd),
+		LegalName:                randomName(rnd),
+		DOTNumber:                randomDigits(rnd, 1+rnd.Intn(8)),
+		PhysicalAddress:          randomAddress(rnd),
+		MailingAddress:           randomAddress(rnd),
+		PowerUnits:               randomCount(rnd),
+		Drivers:                  randomCount(rnd),
+		MCS150FormDate:           maybeDate(rnd),
+		OutOfServiceDate:         maybeDate(rnd),
+		USVehicleInspections:     randomInspections(rnd, true),
+		USDriverInspections:      randomInspections(rnd, true),
+		USHazmatInspections:      randomInspections(rnd, true),
+		USIEPInspections:         randomInspections(rnd, true),
+		CanadaVehicleInspections: randomInspections(rnd, false),
+		CanadaDriverInspections:  randomInspections(rnd, false),
+		USCrashes:                randomCrashes(rnd),
+		CanadaCrashes:            randomCrashes(rnd),
+	}
+	if rnd.Intn(2) == 0 {
+		s.DBAName = randomName(rnd)
+	}
+	if rnd.Intn(2) == 0 {
+		s.Phone = fmt.Sprintf("(%s) %s-%s", randomDigits(rnd, 3), randomDigits(rnd, 3), randomDigits(rnd, 4))
+	}
+	if rnd.Intn(2) == 0 {
+		s.StateCarrierID = randomDigits(rnd, 6)
+	}
+	if rnd.Intn(2) == 0 {
+		s.DUNSNumber = randomDigits(rnd, 9)
+	}
+	if rnd.Intn(4) > 0 {
+		s.MCS150Mileage = rnd.Intn(2000000000)
+		s.MCS150Year = fmt.Sprint(2000 + rnd.Intn(25))
+	}
+
+	statuses := []string{"", "ACTIVE", "AUTHORIZED FOR Property", "AUTHORIZED FOR Passenger, HHG",
+		"NOT AUTHORIZED", "OUT-OF-SERVICE", "INACTIVE USDOT Number", "PENDING"}
+	s.OperatingStatusRaw = statuses[rnd.Intn(len(statuses))]
+	s.OperatingStatus = parseOperatingStatus(s.OperatingStatusRaw)
+
+	for _, entityType := range []EntityType{EntityTypeCarrier, EntityTypeBroker, EntityTypeShipper,
+		EntityTypeFreightForwarder, EntityTypeCargoTank, EntityTypeIntermodalEquipmentProvider, EntityTypeRegistrant} {
+		if rnd.Intn(3) == 0 {
+			s.EntityType = append(s.EntityType, entityType)
+		}
+	}
+	if rnd.Intn(4) == 0 {
+		s.EntityTypeOther = []string{"HAZMAT SHIPPER"}
+	}
+
+	s.MCMXFFNumbers = []string{}
+	for _, prefix := range []string{"MC", "MX", "FF"} {
+		if rnd.Intn(3) == 0 {
+			s.MCMXFFNumbers = append(s.MCMXFFNumbers, prefix+"-"+randomDigits(rnd, 1+rnd.Intn(7)))
+		}
+	}
+	s.DocketNumbers = docketIdentifiers(s.MCMXFFNumbers)
+
+	for _, label := range randomChecked(rnd, operationClassificationGrid) {
+		s.OperationClassification = append(s.OperationClassification, operationClassifications[vocabularyKey(label)])
+	}
+	for _, label := range randomChecked(rnd, carrierOperationGrid) {
+		s.CarrierOperation = append(s.CarrierOperation, carrierOperations[vocabularyKey(label)])
+	}
+	for _, label := range randomChecked(rnd, cargoCarriedGrid) {
+		s.CargoCarried = append(s.CargoCarried, cargoTypes[vocabularyKey(label)])
+	}
+	if rnd.Intn(4) == 0 {
+		s.OperationClassificationOther = []string{"TRIBAL GOV'T"}
+	}
+	if rnd.Intn(4) == 0 {
+		s.CarrierOperationOther = []string{"Intrastate Only (Agricultural)"}
+	}
+	if rnd.Intn(4) == 0 {
+		s.CargoCarriedOther = []string{"CONCRETE", "SAND & GRAVEL"}[:1+rnd.Intn(2)]
+	}
+
+	if rnd.Intn(2) == 0 {
+		ratings := []string{"Satisfactory", "Conditional", "Unsatisfactory", "None", "Pending Review"}
+		s.Safety = SafetyRating{
+			RatingDate: maybeDate(rnd),
+			ReviewDate: maybeDate(rnd),
+			RatingRaw:  ratings[rnd.Intn(len(ratings))],
+			Type:       []string{"Compliance Review", "Non-Ratable Review", "None"}[rnd.Intn(3)],
+		}
+		s.Safety.Rating = parseRating(s.Safety.RatingRaw)
+	}
+	return s
+}
+
+// randomChecked returns a random subset of the labels of grid, in the order they appear on the page
+func randomChecked(rnd *rand.Rand, grid [][]string) []string {
+	var checked []string
+	for _, column := range grid {
+		for _, label := range column {
+			if rnd.Intn(3) == 0 {
+				checked = append(checked, label)
+			}
+		}
+	}
+	return checked
+}
+
+func randomName(rnd *rand.Rand) string {
+	words := []string{"SCHNEIDER", "NATIONAL", "CARRIERS", "INC", "A & B", "O'BRIEN", "<ACME>", "LLC", "J.B.", "FREIGHT"}
+	name := make([]string, 1+rnd.Intn(4))
+	for i := range name {
+		name[i] = words[rnd.Intn(len(words))]
+	}
+	return strings.Join(name, " ")
+}
+
+func randomAddress(rnd *rand.Rand) Address {
+	if rnd.Intn(5) == 0 {
+		return Address{}
+	}
+	addr := Address{
+		Street:  fmt.Sprintf("%d %s", 1+rnd.Intn(9999), []string{"S PACKERLAND DR", "MAIN ST", "PO BOX 2545"}[rnd.Intn(3)]),
+		City:    []string{"GREEN BAY", "O'FALLON", "SAINT PAUL"}[rnd.Intn(3)],
+		State:   []string{"WI", "MO", "MN", "TX"}[rnd.Intn(4)],
+		ZIP:     randomDigits(rnd, 5),
+		Country: "US",
+	}
+	postal := addr.ZIP
+	if rnd.Intn(2) == 0 {
+		addr.ZIP4 = randomDigits(rnd, 4)
+		postal += "-" + addr.ZIP4
+	}
+	addr.Raw = []string{addr.Street, addr.City + ", " + addr.State + "   " + postal}
+	return addr
+}
+
+func randomInspections(rnd *rand.Rand, nationalAverage bool) InspectionSummary {
+	summary := InspectionSummary{
+		Inspections:     rnd.Intn(20000),
+		OutOfService:    rnd.Intn(2000),
+		OutOfServicePct: float32(rnd.Intn(1001)) / 1000,
+	}
+	if nationalAverage {
+		summary.NationalAverage = float32(rnd.Intn(10001)) / 10000
+	}
+	return summary
+}
+
+func randomCrashes(rnd *rand.Rand) CrashSummary {
+	return CrashSummary{Fatal: rnd.Intn(20), Injury: rnd.Intn(500), Tow: rnd.Intn(1000), Total: rnd.Intn(1500)}
+}
+
+func randomCount(rnd *rand.Rand) int {
+	return []int{0, 1, 42, 999, 1000, 10884, 1234567}[rnd.Intn(7)]
+}
+
+func randomDate(rnd *rand.Rand) *time.Time {
+	date := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, rnd.Intn(12000))
+	return &date
+}
+
+func maybeDate(rnd *rand.Rand) *time.Time {
+	if rnd.Intn(3) == 0 {
+		return nil
+	}
+	return randomDate(rnd)
+}
+
+func randomDigits(rnd *rand.Rand, n int) string {
+	digits := make([]byte, n)
+	for i := range digits {
+		digits[i] = byte('0' + rnd.Intn(10))
+	}
+	if digits[0] == '0' && n > 1 {
+		digits[0] = '1'
+	}
+	return string(digits)
+}
@@ -0,0 +1,324 @@
+package safer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// Sections of the company snapshot page, as named in ParseReport.MissingSections and LayoutError
+const (
+	SectionSource            = "source"
+	SectionLatestUpdate      = "latest_update"
+	SectionGeneralInfo       = "general_info"
+	SectionUSInspections     = "us_inspections"
+	SectionUSCrashes         = "us_crashes"
+	SectionCanadaInspections = "canada_inspections"
+	SectionCanadaCrashes     = "canada_crashes"
+	SectionSafetyRating      = "safety_rating"
+)
+
+// fields that every real snapshot has. Strict parsing fails if they're empty.
+var requiredFields = []string{"legal_name", "dot_number"}
+
+func isRequiredField(name string) bool {
+	for _, required := range requiredFields {
+		if name == required {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldStatus describes what the parser found for a single field
+type FieldStatus string
+
+// Field statuses reported in ParseReport
+const (
+	// FieldFound means the field had a value that parsed successfully
+	FieldFound FieldStatus = "found"
+	// FieldEmpty means the field was blank or a placeholder such as "None" or "N/A"
+	FieldEmpty FieldStatus = "empty"
+	// FieldUnparseable means the field had a value that couldn't be parsed into its type
+	FieldUnparseable FieldStatus = "unparseable"
+	// FieldMissing means the field's label wasn't on the page, or had no value after it. Strict parsing fails on
+	// missing fields.
+	FieldMissing FieldStatus = "missing"
+)
+
+// ParseReport describes how well a company snapshot page matched the expected layout. Fields are keyed by their
+// JSON name, with nested fields joined by a dot (e.g. "legal_name", "us_crashes.fatal"). UnknownLabels lists the
+// general info labels the parser doesn't recognize, such as a field SAFER has added.
+type ParseReport struct {
+	Fields          map[string]FieldStatus `json:"fields"`
+	MissingSections []string               `json:"missing_sections"`
+	Warnings        []ParseWarning         `json:"warnings"`
+	UnknownLabels   []string               `json:"unknown_labels"`
+}
+
+// ParseWarning describes a row or cell missing from a section that was otherwise found, such as on a truncated
+// page. The fields it would have held are left at their zero value.
+type ParseWarning struct {
+	Section string `json:"section"`
+	Message string `json:"message"`
+}
+
+func (w ParseWarning) String() string {
+	return w.Section + ": " + w.Message
+}
+
+// FieldsWithStatus returns the sorted names of the fields with the given status
+func (r *ParseReport) FieldsWithStatus(status FieldStatus) []string {
+	var names []string
+	for name, s := range r.Fields {
+		if s == status {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseOptions controls how a company snapshot page is parsed
+type ParseOptions struct {
+	// Strict makes parsing fail with a *LayoutError, matching ErrLayoutChanged, when a section of the page or a
+	// labeled field is missing, or a required field (legal name, DOT number) is empty. Otherwise whatever could be
+	// found is returned.
+	Strict bool
+	// Mapping locates the fields on the page. Defaults to DefaultMapping.
+	Mapping *Mapping
+}
+
+// LayoutError is returned by strict parsing when the page no longer matches the expected layout.
+// errors.Is(err, ErrLayoutChanged) reports true for a *LayoutError.
+type LayoutError struct {
+	MissingSections []string
+	MissingFields   []string
+}
+
+func (e *LayoutError) Error() string {
+	var parts []string
+	if len(e.MissingSections) > 0 {
+		parts = append(parts, "missing sections "+strings.Join(e.MissingSections, ", "))
+	}
+	if len(e.MissingFields) > 0 {
+		parts = append(parts, "missing fields "+strings.Join(e.MissingFields, ", "))
+	}
+	return ErrLayoutChanged.Error() + ": " + strings.Join(parts, "; ")
+}
+
+// Is reports whether target is ErrLayoutChanged or ErrParse
+func (e *LayoutError) Is(target error) bool {
+	return target == ErrLayoutChanged || target == ErrParse
+}
+
+// WithStrictParsing makes the client's snapshot lookups fail with a *LayoutError when SAFER's page layout changes.
+// See ParseOptions.Strict.
+func WithStrictParsing() Option {
+	return func(o *options) {
+		o.parseOptions.Strict = true
+	}
+}
+
+// WithParseReportHook sets a function called with the ParseReport of every snapshot page the client parses, so
+// monitoring can spot layout drift as soon as it happens
+func WithParseReportHook(hook func(*ParseReport)) Option {
+	return func(o *options) {
+		o.parseReportHook = hook
+	}
+}
+
+// ParseCompanySnapshotWithReport is ParseCompanySnapshot that also returns a report of what was found on the page.
+// The report is returned along with ErrLayoutChanged errors so the missing pieces can be inspected.
+func ParseCompanySnapshotWithReport(r io.Reader, opts ParseOptions) (*CompanySnapshot, *ParseReport, error) {
+	node, err := htmlquery.Parse(r)
+	if err != nil {
+		return nil, nil, parseError(err)
+	}
+	return parseCompanySnapshot(node, opts)
+}
+
+// snapshotParser records the status of every field it parses into a ParseReport
+type snapshotParser struct {
+	mapping *compiledMapping
+	report  ParseReport
+}
+
+func newSnapshotParser(mapping *compiledMapping) *snapshotParser {
+	if mapping == nil {
+		mapping = defaultCompiledMapping
+	}
+	return &snapshotParser{
+		mapping: mapping,
+		report:  ParseReport{Fields: make(map[string]FieldStatus, mapping.fields)},
+	}
+}
+
+func (p *snapshotParser) missingSection(section string) {
+	p.report.MissingSections = append(p.report.MissingSections, section)
+}
+
+func (p *snapshotParser) warn(section, format string, args ...interface{}) {
+	p.report.Warnings = append(p.report.Warnings, ParseWarning{
+		Section: section,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (p *snapshotParser) unknownLabel(label string) {
+	p.report.UnknownLabels = append(p.report.UnknownLabels, label)
+}
+
+func (p *snapshotParser) set(name string, status FieldStatus) {
+	p.report.Fields[name] = status
+}
+
+// isEmptyValue reports whether text is blank or a placeholder SAFER shows in place of a value
+func isEmptyValue(text string) bool {
+	switch text {
+	case "", "None", "N/A", "--":
+		return true
+	}
+	return false
+}
+
+func (p *snapshotParser) text(name, text string) string {
+	if isEmptyValue(text) {
+		p.set(name, FieldEmpty)
+	} else {
+		p.set(name, FieldFound)
+	}
+	return text
+}
+
+func (p *snapshotParser) texts(name string, texts []string) []string {
+	if len(texts) == 0 {
+		p.set(name, FieldEmpty)
+	} else {
+		p.set(name, FieldFound)
+	}
+	return texts
+}
+
+func (p *snapshotParser) int(name, text string) int {
+	if isEmptyValue(text) {
+		p.set(name, FieldEmpty)
+		return 0
+	}
+	parsed, ok := parseIntOK(text)
+	if ok {
+		p.set(name, FieldFound)
+	} else {
+		p.set(name, FieldUnparseable)
+	}
+	return parsed
+}
+
+func (p *snapshotParser) pct(name, text string) float32 {
+	if isEmptyValue(text) {
+		p.set(name, FieldEmpty)
+		return 0
+	}
+	parsed, ok := parsePctToFloat32OK(text)
+	if ok {
+		p.set(name, FieldFound)
+	} else {
+		p.set(name, FieldUnparseable)
+	}
+	return parsed
+}
+
+func (p *snapshotParser) date(name, text string) *time.Time {
+	if isEmptyValue(text) {
+		p.set(name, FieldEmpty)
+		return nil
+	}
+	parsed := parseDate(text)
+	if parsed != nil {
+		p.set(name, FieldFound)
+	} else {
+		p.set(name, FieldUnparseable)
+	}
+	return parsed
+}
+
+func (p *snapshotParser) address(name string, texts []string) Address {
+	addr := parseAddress(texts...)
+	switch {
+	case len(addr.Raw) == 0:
+		p.set(name, FieldEmpty)
+	case addr.City == "":
+		p.set(name, FieldUnparseable)
+	default:
+		p.set(name, FieldFound)
+	}
+	return addr
+}
+
+func (p *snapshotParser) mileageYear(name, text string) (mileage int, year string) {
+	if isEmptyValue(text) {
+		p.set(name, FieldEmpty)
+		return
+	}
+	mileage, year = parseMCS150MileageYear(text)
+	if year != "" {
+		p.set(name, FieldFound)
+	} else {
+		p.set(name, FieldUnparseable)
+	}
+	return
+}
+
+// layoutError returns the strict parsing error for the report, or nil if the page matched the expected layout
+func (p *snapshotParser) layoutError() error {
+	var missingFields []string
+	for _, name := range requiredFields {
+		if p.report.Fields[name] != FieldFound {
+			missingFields = append(missingFields, name)
+		}
+	}
+	for _, name := range p.report.FieldsWithStatus(FieldMissing) {
+		if !isRequiredField(name) {
+			missingFields = append(missingFields, name)
+		}
+	}
+	if len(p.report.MissingSections) == 0 && len(missingFields) == 0 {
+		return nil
+	}
+	return &LayoutError{
+		MissingSections: p.report.MissingSections,
+		MissingFields:   missingFields,
+	}
+}
+
+// parseCompanySnapshot parses the page into a snapshot and a report of what was found
+func parseCompanySnapshot(root *html.Node, opts ParseOptions) (*CompanySnapshot, *ParseReport, error) {
+	var mapping *compiledMapping
+	if opts.Mapping != nil {
+		var err error
+		if mapping, err = compileMapping(opts.Mapping); err != nil {
+			return nil, nil, err
+		}
+	}
+	return parseCompiledSnapshot(root, mapping, opts.Strict)
+}
+
+// parseCompiledSnapshot is parseCompanySnapshot with a mapping that's already compiled, nil for the default
+func parseCompiledSnapshot(root *html.Node, mapping *compiledMapping, strict bool) (*CompanySnapshot, *ParseReport, error) {
+	p := newSnapshotParser(mapping)
+	snapshot, err := p.parse(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strict {
+		if err := p.layoutError(); err != nil {
+			return nil, &p.report, err
+		}
+	}
+	return snapshot, &p.report, nil
+}
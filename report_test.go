@@ -0,0 +1,158 @@
+package safer
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// redesignedSnapshot is snapshot-basic.html with the centered section tables unwrapped, as a redesign might do
+func redesignedSnapshot() []byte {
+	data := string(readTestData("./testdata/snapshot-basic.html"))
+	data = strings.ReplaceAll(data, "<CENTER>", "<DIV>")
+	data = strings.ReplaceAll(data, "</CENTER>", "</DIV>")
+	return []byte(data)
+}
+
+func TestParseCompanySnapshotWithReport(t *testing.T) {
+	for _, name := range []string{"snapshot-basic", "snapshot-extras", "snapshot-oos"} {
+		data := readTestData("./testdata/" + name + ".html")
+		snapshot, report, err := ParseCompanySnapshotWithReport(bytes.NewReader(data), ParseOptions{Strict: true})
+		if err != nil {
+			t.Errorf("%s: ParseCompanySnapshotWithReport should return no error, but got %v", name, err)
+			continue
+		}
+		want, _ := ParseCompanySnapshot(bytes.NewReader(data))
+		if !reflect.DeepEqual(snapshot, want) {
+			t.Errorf("%s: ParseCompanySnapshotWithReport() = %v, want %v", name, snapshot, want)
+		}
+		if len(report.MissingSections) > 0 {
+			t.Errorf("%s: MissingSections = %v, want none", name, report.MissingSections)
+		}
+		if got := report.FieldsWithStatus(FieldUnparseable); len(got) > 0 {
+			t.Errorf("%s: unparseable fields = %v, want none", name, got)
+		}
+	}
+
+	_, report, _ := ParseCompanySnapshotWithReport(bytes.NewReader(readTestData("./testdata/snapshot-basic.html")), ParseOptions{})
+	for name, want := range map[string]FieldStatus{
+		"legal_name":                          FieldFound,
+		"us_crashes.total":                    FieldFound,
+		"safety.rating":                       FieldFound,
+		"dba_name":                            FieldEmpty,
+		"out_of_service_date":                 FieldEmpty,
+		"us_iep_inspections.national_average": FieldEmpty,
+	} {
+		if got := report.Fields[name]; got != want {
+			t.Errorf("Fields[%q] = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseCompanySnapshotWithReport_LayoutChanged(t *testing.T) {
+	// the safety rating heading is still on the page, so its table is treated as absent rather than missing
+	wantSections := []string{
+		SectionGeneralInfo,
+		SectionUSInspections,
+		SectionUSCrashes,
+		SectionCanadaInspections,
+		SectionCanadaCrashes,
+	}
+
+	snapshot, report, err := ParseCompanySnapshotWithReport(bytes.NewReader(redesignedSnapshot()), ParseOptions{Strict: true})
+	if !errors.Is(err, ErrLayoutChanged) {
+		t.Fatalf("ParseCompanySnapshotWithReport should return ErrLayoutChanged, but got %v", err)
+	}
+	if snapshot != nil {
+		t.Errorf("snapshot should return nil")
+	}
+	var layoutErr *LayoutError
+	if !errors.As(err, &layoutErr) {
+		t.Fatalf("ParseCompanySnapshotWithReport should return a *LayoutError, but got %T", err)
+	}
+	if !reflect.DeepEqual(layoutErr.MissingSections, wantSections) {
+		t.Errorf("MissingSections = %v, want %v", layoutErr.MissingSections, wantSections)
+	}
+	if want := []string{"legal_name", "dot_number"}; !reflect.DeepEqual(layoutErr.MissingFields, want) {
+		t.Errorf("MissingFields = %v, want %v", layoutErr.MissingFields, want)
+	}
+	if report == nil || !reflect.DeepEqual(report.MissingSections, wantSections) {
+		t.Errorf("report should be returned with the error, got %v", report)
+	}
+
+	// without strict parsing the partial snapshot is returned
+	snapshot, report, err = ParseCompanySnapshotWithReport(bytes.NewReader(redesignedSnapshot()), ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseCompanySnapshotWithReport should return no error, but got %v", err)
+	}
+	if snapshot == nil || snapshot.LatestUpdateDate == nil {
+		t.Errorf("snapshot should keep the fields that were still found, got %v", snapshot)
+	}
+	if !reflect.DeepEqual(report.MissingSections, wantSections) {
+		t.Errorf("MissingSections = %v, want %v", report.MissingSections, wantSections)
+	}
+}
+
+func TestParseCompanySnapshotWithReport_MissingLabel(t *testing.T) {
+	data := strings.Replace(string(readTestData("./testdata/snapshot-basic.html")), "Power Units:", "Vehicles:", 1)
+
+	snapshot, report, err := ParseCompanySnapshotWithReport(strings.NewReader(data), ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseCompanySnapshotWithReport should return no error, but got %v", err)
+	}
+	if snapshot.PowerUnits != 0 {
+		t.Errorf("PowerUnits = %v, want 0", snapshot.PowerUnits)
+	}
+	if got := report.FieldsWithStatus(FieldMissing); !reflect.DeepEqual(got, []string{"power_units"}) {
+		t.Errorf("missing fields = %v, want [power_units]", got)
+	}
+	wantWarning := ParseWarning{Section: SectionGeneralInfo, Message: `label "Power Units:" not found`}
+	if !reflect.DeepEqual(report.Warnings, []ParseWarning{wantWarning}) {
+		t.Errorf("Warnings = %v, want [%v]", report.Warnings, wantWarning)
+	}
+	if !reflect.DeepEqual(report.UnknownLabels, []string{"Vehicles:"}) {
+		t.Errorf("UnknownLabels = %v, want [Vehicles:]", report.UnknownLabels)
+	}
+
+	_, _, err = ParseCompanySnapshotWithReport(strings.NewReader(data), ParseOptions{Strict: true})
+	var layoutErr *LayoutError
+	if !errors.As(err, &layoutErr) {
+		t.Fatalf("ParseCompanySnapshotWithReport should return a *LayoutError, but got %v", err)
+	}
+	if want := []string{"power_units"}; !reflect.DeepEqual(layoutErr.MissingFields, want) {
+		t.Errorf("MissingFields = %v, want %v", layoutErr.MissingFields, want)
+	}
+	if len(layoutErr.MissingSections) > 0 {
+		t.Errorf("MissingSections = %v, want none", layoutErr.MissingSections)
+	}
+}
+
+func TestClient_StrictParsing(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(redesignedSnapshot())
+	}))
+	defer ts.Close()
+
+	var reports []*ParseReport
+	c := NewClient(
+		WithBaseURL(ts.URL),
+		WithStrictParsing(),
+		WithParseReportHook(func(r *ParseReport) { reports = append(reports, r) }),
+	)
+	if _, err := c.GetCompanyByDOTNumber("264184"); !errors.Is(err, ErrLayoutChanged) {
+		t.Errorf("GetCompanyByDOTNumber should return ErrLayoutChanged, but got %v", err)
+	}
+	if len(reports) != 1 || len(reports[0].MissingSections) == 0 {
+		t.Errorf("report hook should be called once with the missing sections, got %v", reports)
+	}
+
+	c = NewClient(WithBaseURL(ts.URL))
+	if _, err := c.GetCompanyByDOTNumber("264184"); err != nil {
+		t.Errorf("GetCompanyByDOTNumber should return no error without strict parsing, but got %v", err)
+	}
+}
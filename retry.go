@@ -0,0 +1,206 @@
+package safer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how lookups that fail with a transient error are retried. Only transport errors, which
+// match ErrUpstreamUnavailable, and responses with a status in RetryableStatuses are retried. Any other error, such
+// as a malformed URL or a corrupt response body, is returned at once. The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first. Values less than 2 disable retries.
+	MaxAttempts int
+	// BaseBackoff is the wait before the first retry. It doubles for every following retry.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, including a longer wait asked for by a Retry-After header.
+	MaxBackoff time.Duration
+	// Jitter randomizes each wait by up to this fraction of itself (e.g. 0.2 for +/-20%).
+	Jitter float64
+	// RetryableStatuses are the HTTP status codes that are retried.
+	RetryableStatuses []int
+	// OnAttempt, when set, is called after every attempt with its outcome.
+	OnAttempt func(Attempt)
+}
+
+// Attempt describes the outcome of a single request to SAFER
+type Attempt struct {
+	// Number of the attempt, starting at 1
+	Number int
+	// URL requested
+	URL string
+	// StatusCode of the response, 0 if no response was received
+	StatusCode int
+	// Err returned by the attempt, nil on success
+	Err error
+	// Delay before the next attempt, 0 if no retry will be made
+	Delay time.Duration
+}
+
+// DefaultRetryPolicy is used by NewClient unless overridden with WithRetryPolicy. It makes up to 3 attempts,
+// backing off from 500ms, and retries 429 and 5xx gateway statuses.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: 500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		Jitter:      0.2,
+		RetryableStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry lookups. Pass RetryPolicy{} to disable retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// lookup sends a read-only query to SAFER, retrying transient failures according to the retry policy. SAFER
+// requires POST for its queries but they do not modify anything, so they are safe to repeat.
+func (s *scraper) lookup(ctx context.Context, reqURL string) ([]byte, error) {
+	var body []byte
+	err := s.retry(ctx, reqURL, func() (err error) {
+		body, err = s.postRequest(ctx, reqURL)
+		return err
+	})
+	return body, err
+}
+
+// lookupStream is lookup for a response read as it arrives. Only sending the request is retried, so failures
+// reading the body are returned by the reader. The caller must close the body.
+func (s *scraper) lookupStream(ctx context.Context, reqURL string) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := s.retry(ctx, reqURL, func() (err error) {
+		body, err = s.openRequest(ctx, reqURL)
+		return err
+	})
+	return body, err
+}
+
+// retry calls attempt until it succeeds or fails with an error the retry policy doesn't retry
+func (s *scraper) retry(ctx context.Context, reqURL string, attempt func() error) error {
+	for number := 1; ; number++ {
+		if err := s.rateLimiter.wait(ctx); err != nil {
+			return err
+		}
+		err := attempt()
+		info := Attempt{Number: number, URL: reqURL, Err: err}
+		var retry bool
+		if err == nil {
+			info.StatusCode = http.StatusOK
+		} else if ctx.Err() == nil {
+			info.StatusCode, info.Delay, retry = s.retryPolicy.next(number, err)
+			if retry {
+				if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < info.Delay {
+					// the next attempt could not finish before the deadline
+					info.Delay, retry = 0, false
+				}
+			}
+		}
+		if s.retryPolicy.OnAttempt != nil {
+			s.retryPolicy.OnAttempt(info)
+		}
+		if !retry {
+			return err
+		}
+		if err := sleepContext(ctx, info.Delay); err != nil {
+			return err
+		}
+	}
+}
+
+// next returns the status code of the failed attempt, and whether and how long to wait before retrying
+func (p *RetryPolicy) next(attempt int, err error) (statusCode int, delay time.Duration, retry bool) {
+	var retryAfter time.Duration
+	var httpErr *HTTPError
+	switch {
+	case errors.Is(err, ErrUnsupportedEncoding):
+		// the same response would come back
+		return http.StatusOK, 0, false
+	case errors.As(err, &httpErr):
+		statusCode, retryAfter = httpErr.StatusCode, httpErr.RetryAfter
+		if !p.retryableStatus(statusCode) {
+			return statusCode, 0, false
+		}
+	case !errors.Is(err, ErrUpstreamUnavailable):
+		// not a transport error, so retrying would fail the same way
+		return 0, 0, false
+	}
+	if attempt >= p.MaxAttempts {
+		return statusCode, 0, false
+	}
+	delay = p.backoff(attempt)
+	if p.MaxBackoff > 0 && retryAfter > p.MaxBackoff {
+		retryAfter = p.MaxBackoff
+	}
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	return statusCode, delay, true
+}
+
+func (p *RetryPolicy) retryableStatus(statusCode int) bool {
+	for _, code := range p.RetryableStatuses {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the exponential backoff with jitter to wait after the given attempt
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration(float64(delay) * p.Jitter * (2*rand.Float64() - 1))
+	}
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header given in either seconds or as an HTTP date
+func parseRetryAfter(text string) time.Duration {
+	if text == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(text); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(text); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
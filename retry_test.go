@@ -0,0 +1,236 @@
+package safer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFlakyServer(failures int32, status int, retryAfter string) (*httptest.Server, *int32) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			if status == 0 {
+				// simulate a connection reset
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(readTestData("./testdata/snapshot-basic.html"))
+	}))
+	return ts, &calls
+}
+
+func testRetryPolicy(attempts *[]Attempt) RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	policy.OnAttempt = func(a Attempt) {
+		*attempts = append(*attempts, a)
+	}
+	return policy
+}
+
+func TestLookup_RetriesTransientFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "service unavailable", status: http.StatusServiceUnavailable},
+		{name: "bad gateway", status: http.StatusBadGateway},
+		{name: "connection reset", status: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, calls := newFlakyServer(2, tt.status, "")
+			defer ts.Close()
+
+			var attempts []Attempt
+			s := &scraper{companySnapshotURL: ts.URL, retryPolicy: testRetryPolicy(&attempts)}
+			snapshot, err := s.scrapeCompanySnapshot(context.Background(), paramUSDOT, "264184")
+			if err != nil {
+				t.Errorf("scrapeCompanySnapshot should return no error, but got %v", err)
+			}
+			if snapshot == nil {
+				t.Errorf("snapshot should not return nil")
+			}
+			if *calls != 3 {
+				t.Errorf("server calls = %v, want %v", *calls, 3)
+			}
+			if len(attempts) != 3 {
+				t.Fatalf("attempts reported = %v, want %v", len(attempts), 3)
+			}
+			if attempts[0].Err == nil || attempts[0].StatusCode != tt.status || attempts[0].Delay <= 0 {
+				t.Errorf("first attempt = %+v, want failed attempt with status %v and a delay", attempts[0], tt.status)
+			}
+			if attempts[2].Err != nil || attempts[2].StatusCode != http.StatusOK || attempts[2].Number != 3 {
+				t.Errorf("last attempt = %+v, want successful third attempt", attempts[2])
+			}
+		})
+	}
+}
+
+func TestLookup_GivesUpAfterMaxAttempts(t *testing.T) {
+	ts, calls := newFlakyServer(10, http.StatusServiceUnavailable, "")
+	defer ts.Close()
+
+	var attempts []Attempt
+	s := &scraper{companySnapshotURL: ts.URL, retryPolicy: testRetryPolicy(&attempts)}
+	if _, err := s.scrapeCompanySnapshot(context.Background(), paramUSDOT, "264184"); err == nil {
+		t.Errorf("scrapeCompanySnapshot should return an error")
+	}
+	if *calls != 3 {
+		t.Errorf("server calls = %v, want %v", *calls, 3)
+	}
+	if last := attempts[len(attempts)-1]; last.Delay != 0 {
+		t.Errorf("last attempt delay = %v, want 0", last.Delay)
+	}
+}
+
+func TestLookup_DoesNotRetryClientErrors(t *testing.T) {
+	ts, calls := newFlakyServer(10, http.StatusBadRequest, "")
+	defer ts.Close()
+
+	var attempts []Attempt
+	s := &scraper{companySnapshotURL: ts.URL, retryPolicy: testRetryPolicy(&attempts)}
+	if _, err := s.scrapeCompanySnapshot(context.Background(), paramUSDOT, "264184"); err == nil {
+		t.Errorf("scrapeCompanySnapshot should return an error")
+	}
+	if *calls != 1 {
+		t.Errorf("server calls = %v, want %v", *calls, 1)
+	}
+}
+
+func TestLookup_RetryAfterPastDeadline(t *testing.T) {
+	ts, calls := newFlakyServer(10, http.StatusTooManyRequests, "120")
+	defer ts.Close()
+
+	var attempts []Attempt
+	policy := testRetryPolicy(&attempts)
+	policy.MaxBackoff = 5 * time.Minute
+	s := &scraper{companySnapshotURL: ts.URL, retryPolicy: policy}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := s.scrapeCompanySnapshot(ctx, paramUSDOT, "264184"); err == nil {
+		t.Errorf("scrapeCompanySnapshot should return an error")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("lookup should give up immediately when Retry-After exceeds the deadline")
+	}
+	if *calls != 1 {
+		t.Errorf("server calls = %v, want %v", *calls, 1)
+	}
+}
+
+func TestLookup_RetryAfterCappedByMaxBackoff(t *testing.T) {
+	ts, calls := newFlakyServer(2, http.StatusServiceUnavailable, "120")
+	defer ts.Close()
+
+	var attempts []Attempt
+	s := &scraper{companySnapshotURL: ts.URL, retryPolicy: testRetryPolicy(&attempts)}
+	if _, err := s.scrapeCompanySnapshot(context.Background(), paramUSDOT, "264184"); err != nil {
+		t.Errorf("scrapeCompanySnapshot should return no error, but got %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("server calls = %v, want %v", *calls, 3)
+	}
+	if delay := attempts[0].Delay; delay > 5*time.Millisecond {
+		t.Errorf("first attempt delay = %v, want at most MaxBackoff", delay)
+	}
+}
+
+func TestLookup_DoesNotRetryPermanentErrors(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("not gzip"))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name      string
+		url       string
+		wantCalls int32
+	}{
+		{name: "corrupt gzip header", url: ts.URL, wantCalls: 1},
+		{name: "malformed URL", url: "http://[::1", wantCalls: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			var attempts []Attempt
+			s := &scraper{companySnapshotURL: tt.url, retryPolicy: testRetryPolicy(&attempts)}
+			if _, err := s.scrapeCompanySnapshot(context.Background(), paramUSDOT, "264184"); err == nil {
+				t.Errorf("scrapeCompanySnapshot should return an error")
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("server calls = %v, want %v", got, tt.wantCalls)
+			}
+			if len(attempts) != 1 || attempts[0].Delay != 0 {
+				t.Errorf("attempts = %+v, want one attempt without a retry", attempts)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 3, want: 400 * time.Millisecond},
+		{attempt: 5, want: time.Second},
+		{attempt: 60, want: time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%v) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("backoff(1) with jitter = %v, want within [50ms, 150ms]", got)
+		}
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want time.Duration
+	}{
+		{name: "seconds", text: "3", want: 3 * time.Second},
+		{name: "empty", text: "", want: 0},
+		{name: "invalid", text: "soon", want: 0},
+		{name: "past date", text: "Mon, 02 Jan 2006 15:04:05 GMT", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.text); got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > time.Minute {
+		t.Errorf("parseRetryAfter(%v) = %v, want within (0, 1m]", future, got)
+	}
+}
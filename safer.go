@@ -1,9 +1,32 @@
 package safer
 
-// NewClient build's a new Client interface
-func NewClient() *Client {
+import "context"
+
+// NewClient build's a new Client interface. Options may be passed to override the http.Client, base URL,
+// headers, or timeout used for requests.
+func NewClient(opts ...Option) *Client {
+	o := newOptions(opts...)
+	var mapping *compiledMapping
+	var mappingErr error
+	if o.parseOptions.Mapping != nil {
+		mapping, mappingErr = compileMapping(o.parseOptions.Mapping)
+	}
 	return &Client{
-		scraper: scraper{},
+		scraper: scraper{
+			httpClient:         o.httpClient,
+			headers:            o.headers,
+			retryPolicy:        o.retryPolicy,
+			rateLimiter:        o.rateLimiter,
+			cache:              o.cache,
+			cacheTTL:           o.cacheTTL,
+			cacheStats:         new(cacheStats),
+			strict:             o.parseOptions.Strict,
+			mapping:            mapping,
+			mappingErr:         mappingErr,
+			parseReportHook:    o.parseReportHook,
+			companySnapshotURL: o.baseURL + companySnapshotPath,
+			searchURL:          o.baseURL + searchPath,
+		},
 	}
 }
 
@@ -12,22 +35,70 @@ type Client struct {
 	scraper
 }
 
+// Interface is the lookup and search methods of Client, for code that wants to accept a fake in tests. The
+// safertest package serves realistic SAFER pages to a real Client for tests that don't want to write a fake.
+//
+// CacheStats and RateLimit are left out on purpose: they report on the Client's own cache and rate limiter, which
+// a fake doesn't have. Code that needs them can hold on to the *Client as well.
+type Interface interface {
+	GetCompanyByDOTNumber(dotNumber string) (*CompanySnapshot, error)
+	GetCompanyByDOTNumberContext(ctx context.Context, dotNumber string) (*CompanySnapshot, error)
+	GetCompanyByMCMX(mcmx string) (*CompanySnapshot, error)
+	GetCompanyByMCMXContext(ctx context.Context, mcmx string) (*CompanySnapshot, error)
+	Lookup(ctx context.Context, id Identifier) (*CompanySnapshot, error)
+	GetCompaniesByDOTNumbers(ctx context.Context, dotNumbers []string, opts BatchOptions) []BatchResult
+	GetCompaniesByMCMXs(ctx context.Context, mcmxs []string, opts BatchOptions) []BatchResult
+	SearchCompaniesByName(name string) ([]CompanyResult, error)
+	SearchCompaniesByNameContext(ctx context.Context, name string) ([]CompanyResult, error)
+	SearchCompaniesByNameFunc(ctx context.Context, name string, fn func(CompanyResult) bool) error
+	SearchCompanies(ctx context.Context, query string, opts SearchOptions) ([]CompanyResult, error)
+	SearchCompaniesFunc(ctx context.Context, query string, opts SearchOptions, fn func(CompanyResult) bool) error
+}
+
+var _ Interface = (*Client)(nil)
+
 // GetCompanyByDOTNumber - Get a company snapshot by the companies DOT number. Returns ErrCompanyNotFound if
-// no company is found
+// no company is found, or ErrCompanyInactive if the company's record has been deactivated. A number that isn't
+// digits only (after stripping a "USDOT" prefix) returns ErrInvalidIdentifier without sending a request.
 func (c *Client) GetCompanyByDOTNumber(dotNumber string) (*CompanySnapshot, error) {
-	return c.scraper.scrapeCompanySnapshot(paramUSDOT, dotNumber)
+	return c.GetCompanyByDOTNumberContext(context.Background(), dotNumber)
+}
+
+// GetCompanyByDOTNumberContext - Same as GetCompanyByDOTNumber but carries the given context through the request
+// and parse. If the context is canceled or its deadline passes, the context's error is returned.
+func (c *Client) GetCompanyByDOTNumberContext(ctx context.Context, dotNumber string) (*CompanySnapshot, error) {
+	return c.scraper.getCompany(ctx, paramUSDOT, dotNumber)
 }
 
 // GetCompanyByMCMX - Get a company snapshot by the companies MC/MX number. Returns ErrCompanyNotFound if no
-// company is found.
-//
-// Note: do not include the prefix. (e.g. use "133655" not "MC-133655")
+// company is found, or ErrCompanyInactive if the company's record has been deactivated. An "MC" or "MX" prefix
+// is stripped (e.g. "MC-133655" looks up "133655"), and an MX number only finds a company whose snapshot lists it.
+// Any other prefix, or anything else that isn't a digit, returns ErrInvalidIdentifier without sending a request.
 func (c *Client) GetCompanyByMCMX(mcmx string) (*CompanySnapshot, error) {
-	return c.scraper.scrapeCompanySnapshot(paramMCMX, mcmx)
+	return c.GetCompanyByMCMXContext(context.Background(), mcmx)
+}
+
+// GetCompanyByMCMXContext - Same as GetCompanyByMCMX but carries the given context through the request
+// and parse. If the context is canceled or its deadline passes, the context's error is returned.
+func (c *Client) GetCompanyByMCMXContext(ctx context.Context, mcmx string) (*CompanySnapshot, error) {
+	return c.scraper.getCompany(ctx, paramMCMX, mcmx)
 }
 
 // SearchCompaniesByName - Search for all carriers with a given name. Name queries will return the best matched results
 // in a slice of CompanyResult structs.
 func (c *Client) SearchCompaniesByName(name string) ([]CompanyResult, error) {
-	return c.scraper.scrapeCompanyNameSearch(name)
+	return c.SearchCompaniesByNameContext(context.Background(), name)
+}
+
+// SearchCompaniesByNameContext - Same as SearchCompaniesByName but carries the given context through the request
+// and parse. If the context is canceled or its deadline passes, the context's error is returned.
+func (c *Client) SearchCompaniesByNameContext(ctx context.Context, name string) ([]CompanyResult, error) {
+	return c.scraper.scrapeCompanySearch(ctx, name, SearchOptions{})
+}
+
+// SearchCompaniesByNameFunc - Same as SearchCompaniesByNameContext but calls fn with each result as it's read from
+// the response instead of collecting them in a slice. Return false from fn to stop early, which closes the response
+// without reading the rest of it.
+func (c *Client) SearchCompaniesByNameFunc(ctx context.Context, name string, fn func(CompanyResult) bool) error {
+	return c.scraper.streamCompanySearch(ctx, name, SearchOptions{}, fn)
 }
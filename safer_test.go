@@ -1,6 +1,7 @@
 package safer
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/antchfx/htmlquery"
@@ -23,7 +24,7 @@ func TestClient_GetCompanyByDOTNumber(t *testing.T) {
 			searchURL:          s.URL + "/search",
 		},
 	}
-	snapshot, err := c.GetCompanyByDOTNumber("")
+	snapshot, err := c.GetCompanyByDOTNumber("264184")
 	if snapshot == nil {
 		t.Error("snapshot returned nil")
 	}
@@ -31,7 +32,7 @@ func TestClient_GetCompanyByDOTNumber(t *testing.T) {
 		t.Errorf("error expected nil but got %v", err)
 	}
 
-	snapshot, err = c.GetCompanyByMCMX("")
+	snapshot, err = c.GetCompanyByMCMX("MC-133655")
 	if snapshot == nil {
 		t.Error("snapshot returned nil")
 	}
@@ -51,15 +52,42 @@ func TestClient_GetCompanyByDOTNumber(t *testing.T) {
 	}
 }
 
-func BenchmarkClient_GetCompanyByDOTNumber(b *testing.B) {
+// maxSnapshotParseAllocs is a third of the 2,672 allocations per parse the xpath-per-field parser made
+const maxSnapshotParseAllocs = 890
+
+func TestParseCompanySnapshot_Allocs(t *testing.T) {
 	node, _ := htmlquery.LoadDoc("./testdata/snapshot-basic.html")
-	b.ReportAllocs()
-	b.ResetTimer()
-	for n := 0; n < b.N; n++ {
+	allocs := testing.AllocsPerRun(100, func() {
 		_, _ = htmlNodeToCompanySnapshot(node)
+	})
+	if allocs > maxSnapshotParseAllocs {
+		t.Errorf("htmlNodeToCompanySnapshot() made %v allocations, want at most %d", allocs, maxSnapshotParseAllocs)
 	}
 }
 
+func BenchmarkClient_GetCompanyByDOTNumber(b *testing.B) {
+	data := readTestData("./testdata/snapshot-basic.html")
+	node, _ := htmlquery.Parse(bytes.NewReader(data))
+	b.Run("parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			_, _ = htmlNodeToCompanySnapshot(node)
+		}
+	})
+	b.Run("strict", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			_, _, _ = parseCompanySnapshot(node, ParseOptions{Strict: true})
+		}
+	})
+	b.Run("html", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			_, _ = ParseCompanySnapshot(bytes.NewReader(data))
+		}
+	})
+}
+
 func BenchmarkClient_Search_4Results(b *testing.B) {
 	node, _ := htmlquery.LoadDoc("./testdata/search-result-short.html")
 	b.ReportAllocs()
@@ -0,0 +1,51 @@
+package safertest
+
+import _ "embed" // for the SAFER pages served by Server
+
+// The pages are copies of the package's test pages, so both are kept in one place. Run go generate after changing
+// testdata.
+//go:generate cp ../testdata/snapshot-basic.html pages/snapshot.html
+//go:generate cp ../testdata/not-found.html pages/not-found.html
+//go:generate cp ../testdata/synthetic/inactive.html pages/inactive.html
+//go:generate cp ../testdata/synthetic/request-rejected.html pages/request-rejected.html
+//go:generate cp ../testdata/synthetic/captcha.html pages/captcha.html
+//go:generate cp ../testdata/synthetic/maintenance.html pages/maintenance.html
+
+var (
+	//go:embed pages/snapshot.html
+	snapshotPage []byte
+	//go:embed pages/not-found.html
+	notFoundPage []byte
+	//go:embed pages/inactive.html
+	inactivePage []byte
+	//go:embed pages/request-rejected.html
+	requestRejectedPage []byte
+	//go:embed pages/captcha.html
+	captchaPage []byte
+	//go:embed pages/maintenance.html
+	maintenancePage []byte
+)
+
+// SnapshotPage returns a captured SAFER company snapshot page, for SCHNEIDER NATIONAL CARRIERS INC (USDOT 264184,
+// MC-133655)
+func SnapshotPage() []byte {
+	return append([]byte(nil), snapshotPage...)
+}
+
+// RequestRejectedPage returns a request rejected page like the one SAFER's firewall sends with a 200 status to
+// clients it has blocked. It's synthetic, not a capture. Parsing it returns safer.ErrBlocked.
+func RequestRejectedPage() []byte {
+	return append([]byte(nil), requestRejectedPage...)
+}
+
+// CaptchaPage returns a captcha page, as sent in place of the requested page. It's synthetic, not a capture.
+// Parsing it returns safer.ErrBlocked.
+func CaptchaPage() []byte {
+	return append([]byte(nil), captchaPage...)
+}
+
+// MaintenancePage returns a maintenance notice. It's synthetic, not a capture. Parsing it returns
+// safer.ErrMaintenance.
+func MaintenancePage() []byte {
+	return append([]byte(nil), maintenancePage...)
+}
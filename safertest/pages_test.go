@@ -0,0 +1,30 @@
+package safertest
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestPages_MatchTestdata(t *testing.T) {
+	tests := []struct {
+		page     []byte
+		testdata string
+	}{
+		{snapshotPage, "../testdata/snapshot-basic.html"},
+		{notFoundPage, "../testdata/not-found.html"},
+		{inactivePage, "../testdata/synthetic/inactive.html"},
+		{requestRejectedPage, "../testdata/synthetic/request-rejected.html"},
+		{captchaPage, "../testdata/synthetic/captcha.html"},
+		{maintenancePage, "../testdata/synthetic/maintenance.html"},
+	}
+	for _, tt := range tests {
+		want, err := os.ReadFile(tt.testdata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(tt.page, want) {
+			t.Errorf("page copied from %s differs, run go generate", tt.testdata)
+		}
+	}
+}
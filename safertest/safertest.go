@@ -0,0 +1,274 @@
+// Package safertest provides a fake SAFER server for testing code that uses safer.Client. The server sends the
+// same HTML SAFER does, so responses go through the client's real request, retry and parsing code.
+//
+//	srv := safertest.NewServer()
+//	defer srv.Close()
+//	srv.AddCarrier(safertest.SnapshotPage())
+//	client := srv.Client()
+//	snapshot, err := client.GetCompanyByDOTNumber("264184")
+package safertest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brandenc40/safer"
+)
+
+// request paths and query parameters used by safer.Client
+const (
+	snapshotPath = "/query.asp"
+	searchPath   = "/keywordx.asp"
+	paramUSDOT   = "USDOT"
+	paramMCMX    = "MC_MX"
+)
+
+// Server is a fake SAFER server. Lookups of carriers added with AddCarrier return their snapshot page, and lookups
+// of any other number return SAFER's record not found page. Name searches list the added carriers whose legal name
+// matches.
+type Server struct {
+	// URL of the server, e.g. "http://127.0.0.1:51234", for safer.WithBaseURL
+	URL string
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	pages    map[string][]byte              // snapshot pages by lookupKey
+	carriers map[string]safer.CompanyResult // search results by DOT number
+	latency  time.Duration
+	faults   []Fault
+	fault    *Fault // sent for every request, after faults
+	requests int
+}
+
+// Fault is a response sent in place of the requested page
+type Fault struct {
+	// StatusCode of the response. Defaults to 200, which sends Body as if it were the requested page.
+	StatusCode int
+	// Body of the response, such as MaintenancePage()
+	Body []byte
+	// RetryAfter sets the Retry-After header, in whole seconds, when positive
+	RetryAfter time.Duration
+}
+
+// NewServer starts a fake SAFER server with no carriers. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		pages:    make(map[string][]byte),
+		carriers: make(map[string]safer.CompanyResult),
+	}
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a safer.Client that sends its requests to the server. opts are applied after the base URL, e.g.
+// safer.WithRetryPolicy(safer.RetryPolicy{}) to see faults without waiting on retries.
+func (s *Server) Client(opts ...safer.Option) *safer.Client {
+	return safer.NewClient(append([]safer.Option{safer.WithBaseURL(s.URL)}, opts...)...)
+}
+
+// AddCarrier serves page, a SAFER company snapshot page, for lookups of the carrier's USDOT number and each of its
+// MC/MX/FF numbers, and includes the carrier in name searches. Adding a page for the same USDOT number again
+// replaces it. Returns the parsed snapshot, or an error if page isn't a snapshot page.
+func (s *Server) AddCarrier(page []byte) (*safer.CompanySnapshot, error) {
+	snapshot, err := safer.ParseCompanySnapshot(bytes.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.DOTNumber == "" {
+		return nil, errors.New("safertest: snapshot page has no USDOT number")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[lookupKey(paramUSDOT, snapshot.DOTNumber)] = page
+	for _, id := range snapshot.DocketNumbers {
+		s.pages[lookupKey(paramMCMX, id.Number)] = page
+	}
+	s.carriers[snapshot.DOTNumber] = safer.CompanyResult{
+		Name:      snapshot.LegalName,
+		DOTNumber: snapshot.DOTNumber,
+		Location:  location(snapshot.PhysicalAddress),
+	}
+	return snapshot, nil
+}
+
+// AddSnapshot serves a page rendered from snapshot with safer.RenderCompanySnapshot, as AddCarrier does. Use it to
+// test carriers no captured page covers.
+func (s *Server) AddSnapshot(snapshot *safer.CompanySnapshot) error {
+	var page bytes.Buffer
+	if err := safer.RenderCompanySnapshot(&page, snapshot); err != nil {
+		return err
+	}
+	_, err := s.AddCarrier(page.Bytes())
+	return err
+}
+
+// AddInactive serves a record inactive page for lookups of id. The page is synthetic, SAFER's record not found page
+// edited to carry the record inactive title and message, not a capture.
+func (s *Server) AddInactive(id safer.Identifier) {
+	param := paramMCMX
+	if id.Type == safer.IdentifierUSDOT {
+		param = paramUSDOT
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[lookupKey(param, id.Number)] = inactivePage
+}
+
+// SetLatency delays every response by d, or until the request is canceled
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// FailNext sends f in place of the next n responses, after any faults already queued
+func (s *Server) FailNext(n int, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.faults = append(s.faults, f)
+	}
+}
+
+// FailAll sends f in place of every response, once the faults queued by FailNext have been sent, until
+// ClearFaults is called
+func (s *Server) FailAll(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = &f
+}
+
+// ClearFaults removes the faults set by FailNext and FailAll
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults, s.fault = nil, nil
+}
+
+// Requests returns the number of requests the server has received
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// ServeHTTP serves snapshot and search requests as SAFER does
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	fault := s.nextFault()
+	s.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "text/html")
+	if fault != nil {
+		if fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter/time.Second)))
+		}
+		if fault.StatusCode != 0 {
+			w.WriteHeader(fault.StatusCode)
+		}
+		w.Write(fault.Body)
+		return
+	}
+	switch r.URL.Path {
+	case snapshotPath:
+		s.serveSnapshot(w, r)
+	case searchPath:
+		s.serveSearch(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// nextFault returns the fault to send in place of the next response, or nil. s.mu must be held.
+func (s *Server) nextFault() *Fault {
+	if len(s.faults) > 0 {
+		f := s.faults[0]
+		s.faults = s.faults[1:]
+		return &f
+	}
+	return s.fault
+}
+
+func (s *Server) serveSnapshot(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	s.mu.Lock()
+	page, ok := s.pages[lookupKey(query.Get("query_param"), query.Get("query_string"))]
+	s.mu.Unlock()
+	if !ok {
+		page = notFoundPage
+	}
+	w.Write(page)
+}
+
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	pattern := r.URL.Query().Get("searchstring")
+	s.mu.Lock()
+	results := []safer.CompanyResult{}
+	for _, result := range s.carriers {
+		if matches(result.Name, pattern) {
+			results = append(results, result)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	if err := safer.RenderSearchResults(w, results); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// lookupKey identifies a snapshot page by the query_param and query_string of its request
+func lookupKey(param, number string) string {
+	return param + ":" + number
+}
+
+// matches reports whether name matches a SAFER search string, where a leading or trailing "*" matches anything.
+// Case is ignored.
+func matches(name, pattern string) bool {
+	name, pattern = strings.ToUpper(name), strings.ToUpper(pattern)
+	query := strings.Trim(pattern, "*")
+	anyPrefix, anySuffix := strings.HasPrefix(pattern, "*"), strings.HasSuffix(pattern, "*") && len(pattern) > 1
+	switch {
+	case anyPrefix && anySuffix:
+		return strings.Contains(name, query)
+	case anyPrefix:
+		return strings.HasSuffix(name, query)
+	case anySuffix:
+		return strings.HasPrefix(name, query)
+	}
+	return name == query
+}
+
+// location is the "CITY, ST" shown for a carrier in search results
+func location(a safer.Address) string {
+	if a.City == "" {
+		return a.State
+	}
+	return a.City + ", " + a.State
+}
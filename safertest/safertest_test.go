@@ -0,0 +1,167 @@
+package safertest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/brandenc40/safer"
+)
+
+func TestServer_Lookups(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	want, err := srv.AddCarrier(SnapshotPage())
+	if err != nil {
+		t.Fatalf("AddCarrier should return no error, but got %v", err)
+	}
+	srv.AddInactive(safer.Identifier{Type: safer.IdentifierUSDOT, Number: "1234"})
+	srv.AddInactive(safer.Identifier{Type: safer.IdentifierMC, Number: "5678"})
+	c := srv.Client()
+
+	if got, err := c.GetCompanyByDOTNumber("264184"); err != nil || got.LegalName != want.LegalName {
+		t.Errorf("GetCompanyByDOTNumber() = %v, %v, want %v", got, err, want.LegalName)
+	}
+	if got, err := c.GetCompanyByMCMX("MC-133655"); err != nil || got.DOTNumber != "264184" {
+		t.Errorf("GetCompanyByMCMX() = %v, %v, want USDOT 264184", got, err)
+	}
+	if _, err := c.GetCompanyByDOTNumber("999"); err != safer.ErrCompanyNotFound {
+		t.Errorf("GetCompanyByDOTNumber() error = %v, want ErrCompanyNotFound", err)
+	}
+	if _, err := c.GetCompanyByDOTNumber("1234"); err != safer.ErrCompanyInactive {
+		t.Errorf("GetCompanyByDOTNumber() error = %v, want ErrCompanyInactive", err)
+	}
+	if _, err := c.GetCompanyByMCMX("5678"); err != safer.ErrCompanyInactive {
+		t.Errorf("GetCompanyByMCMX() error = %v, want ErrCompanyInactive", err)
+	}
+	if got := srv.Requests(); got != 5 {
+		t.Errorf("Requests() = %d, want 5", got)
+	}
+}
+
+func TestServer_AddCarrier_NotSnapshot(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	if _, err := srv.AddCarrier(MaintenancePage()); !errors.Is(err, safer.ErrMaintenance) {
+		t.Errorf("AddCarrier() error = %v, want ErrMaintenance", err)
+	}
+}
+
+func TestServer_Search(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	if _, err := srv.AddCarrier(SnapshotPage()); err != nil {
+		t.Fatal(err)
+	}
+	c := srv.Client()
+
+	results, err := c.SearchCompaniesByName("schneider")
+	if err != nil {
+		t.Fatalf("SearchCompaniesByName should return no error, but got %v", err)
+	}
+	want := safer.CompanyResult{Name: "SCHNEIDER NATIONAL CARRIERS INC", DOTNumber: "264184", Location: "GREEN BAY, WI"}
+	if len(results) != 1 || results[0] != want {
+		t.Errorf("SearchCompaniesByName() = %v, want [%v]", results, want)
+	}
+	results, err = c.SearchCompanies(context.Background(), "national", safer.SearchOptions{Match: safer.MatchPrefix})
+	if err != nil || len(results) != 0 {
+		t.Errorf("SearchCompanies() = %v, %v, want no results", results, err)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	if _, err := srv.AddCarrier(SnapshotPage()); err != nil {
+		t.Fatal(err)
+	}
+	c := srv.Client(safer.WithRetryPolicy(safer.RetryPolicy{}))
+
+	srv.FailNext(1, Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: 30 * time.Second})
+	var httpErr *safer.HTTPError
+	if _, err := c.GetCompanyByDOTNumber("264184"); !errors.As(err, &httpErr) || httpErr.RetryAfter != 30*time.Second {
+		t.Errorf("GetCompanyByDOTNumber() error = %v, want a 429 with RetryAfter 30s", err)
+	}
+	if _, err := c.GetCompanyByDOTNumber("264184"); err != nil {
+		t.Errorf("GetCompanyByDOTNumber() after the fault should return no error, but got %v", err)
+	}
+
+	srv.FailAll(Fault{Body: MaintenancePage()})
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetCompanyByDOTNumber("264184"); !errors.Is(err, safer.ErrMaintenance) {
+			t.Errorf("GetCompanyByDOTNumber() error = %v, want ErrMaintenance", err)
+		}
+	}
+	srv.ClearFaults()
+	if _, err := c.GetCompanyByDOTNumber("264184"); err != nil {
+		t.Errorf("GetCompanyByDOTNumber() after ClearFaults should return no error, but got %v", err)
+	}
+}
+
+func TestServer_Latency(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := srv.Client().GetCompanyByDOTNumberContext(ctx, "264184"); err != context.DeadlineExceeded {
+		t.Errorf("GetCompanyByDOTNumberContext() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"*NATIONAL*", true},
+		{"*national*", true},
+		{"SCHNEIDER*", true},
+		{"*CARRIERS INC", true},
+		{"SCHNEIDER NATIONAL CARRIERS INC", true},
+		{"NATIONAL*", false},
+		{"*SCHNEIDER", false},
+		{"SCHNEIDER", false},
+	}
+	for _, tt := range tests {
+		if got := matches("SCHNEIDER NATIONAL CARRIERS INC", tt.pattern); got != tt.want {
+			t.Errorf("matches(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestServer_AddSnapshot(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	want := &safer.CompanySnapshot{
+		LegalName:     "A & B TRUCKING LLC",
+		DOTNumber:     "1234567",
+		MCMXFFNumbers: []string{"MC-765432"},
+		PhysicalAddress: safer.Address{
+			Raw: []string{"100 MAIN ST", "O'FALLON, MO   63366"},
+		},
+		OperatingStatusRaw: "AUTHORIZED FOR Property",
+		OperatingStatus:    safer.OperatingStatusAuthorized,
+		PowerUnits:         12,
+	}
+	if err := srv.AddSnapshot(want); err != nil {
+		t.Fatalf("AddSnapshot should return no error, but got %v", err)
+	}
+	c := srv.Client()
+
+	got, err := c.GetCompanyByMCMX("765432")
+	if err != nil {
+		t.Fatalf("GetCompanyByMCMX should return no error, but got %v", err)
+	}
+	if got.LegalName != want.LegalName || got.OperatingStatus != want.OperatingStatus || got.PowerUnits != 12 ||
+		got.PhysicalAddress.City != "O'FALLON" {
+		t.Errorf("GetCompanyByMCMX() = %+v, want %+v", got, want)
+	}
+	results, err := c.SearchCompaniesByName("A & B")
+	if err != nil || len(results) != 1 || results[0].Location != "O'FALLON, MO" {
+		t.Errorf("SearchCompaniesByName() = %v, %v, want A & B TRUCKING LLC in O'FALLON, MO", results, err)
+	}
+}
@@ -1,76 +1,217 @@
 package safer
 
 import (
-	"errors"
+	"bytes"
+	"context"
+	"io"
 	"net/http"
-	"strings"
+	"net/url"
+	"time"
 
 	"github.com/antchfx/htmlquery"
 	"golang.org/x/net/html"
 )
 
 const (
-	companySnapshotURL = "https://safer.fmcsa.dot.gov/query.asp"
-	searchURL          = "https://safer.fmcsa.dot.gov/keywordx.asp"
-	paramUSDOT         = "USDOT"
-	paramMCMX          = "MC_MX"
+	defaultBaseURL      = "https://safer.fmcsa.dot.gov"
+	companySnapshotPath = "/query.asp"
+	searchPath          = "/keywordx.asp"
+	companySnapshotURL  = defaultBaseURL + companySnapshotPath
+	searchURL           = defaultBaseURL + searchPath
+	paramUSDOT          = "USDOT"
+	paramMCMX           = "MC_MX"
 )
 
-var headers = http.Header{
-	"Accept":                    {"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.9"},
-	"Accept-Encoding":           {"gzip, deflate, br"},
-	"Accept-Language":           {"en-US,en;q=0.9"},
-	"Cache-Control":             {"max-age=0"},
-	"Connection":                {"keep-alive"},
-	"Host":                      {"safer.fmcsa.dot.gov"},
-	"Upgrade-Insecure-Requests": {"1"},
-	"User-Agent":                {"Mozilla/5.0 (Linux; Android 6.0; Nexus 5 Build/MRA58N) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/92.0.4515.131 Mobile Safari/537.36"},
+// defaultHeaders returns the headers sent when none are configured. A new map is returned on every call so no two
+// requests share one.
+func defaultHeaders() http.Header {
+	return http.Header{
+		"Accept":                    {"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.9"},
+		"Accept-Encoding":           {acceptEncoding},
+		"Accept-Language":           {"en-US,en;q=0.9"},
+		"Cache-Control":             {"max-age=0"},
+		"Connection":                {"keep-alive"},
+		"Host":                      {"safer.fmcsa.dot.gov"},
+		"Upgrade-Insecure-Requests": {"1"},
+		"User-Agent":                {"Mozilla/5.0 (Linux; Android 6.0; Nexus 5 Build/MRA58N) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/92.0.4515.131 Mobile Safari/537.36"},
+	}
 }
 
 type scraper struct {
+	httpClient         *http.Client
+	headers            http.Header
+	retryPolicy        RetryPolicy
+	rateLimiter        *rateLimiter
+	cache              Cache
+	cacheTTL           time.Duration
+	cacheStats         *cacheStats
+	strict             bool
+	mapping            *compiledMapping // nil for DefaultMapping
+	mappingErr         error
+	parseReportHook    func(*ParseReport)
 	companySnapshotURL string
 	searchURL          string
 }
 
-func (s *scraper) scrapeCompanySnapshot(queryParam, queryString string) (*CompanySnapshot, error) {
-	params := "?searchType=ANY&query_type=queryCarrierSnapshot&query_param=" + queryParam + "&query_string=" + queryString
-	reqURL := companySnapshotURL
-	if s.companySnapshotURL != "" {
-		reqURL = s.companySnapshotURL
+// getCompany validates the identifier before looking up its snapshot
+func (s *scraper) getCompany(ctx context.Context, queryParam, queryString string) (*CompanySnapshot, error) {
+	id, err := normalizeIdentifier(queryParam, queryString)
+	if err != nil {
+		return nil, err
+	}
+	return s.lookupIdentifier(ctx, id)
+}
+
+func (s *scraper) scrapeCompanySnapshot(ctx context.Context, queryParam, queryString string) (*CompanySnapshot, error) {
+	if s.mappingErr != nil {
+		return nil, s.mappingErr
+	}
+	key := cacheKey(queryParam, queryString)
+	body, cached := s.cacheGet(ctx, key)
+	if !cached {
+		params := url.Values{
+			"searchType":   {"ANY"},
+			"query_type":   {"queryCarrierSnapshot"},
+			"query_param":  {queryParam},
+			"query_string": {queryString},
+		}
+		reqURL := companySnapshotURL
+		if s.companySnapshotURL != "" {
+			reqURL = s.companySnapshotURL
+		}
+		var err error
+		if body, err = s.lookup(ctx, reqURL+"?"+params.Encode()); err != nil {
+			return nil, err
+		}
 	}
-	node, err := postRequestToHTMLNode(reqURL + params)
+	node, err := parseHTML(ctx, body)
 	if err != nil {
 		return nil, err
 	}
-	return htmlNodeToCompanySnapshot(node)
+	snapshot, report, err := parseCompiledSnapshot(node, s.mapping, s.strict)
+	if report != nil && s.parseReportHook != nil {
+		s.parseReportHook(report)
+	}
+	if err == nil && !cached {
+		s.cacheSet(key, body)
+	}
+	return snapshot, err
 }
 
-func (s *scraper) scrapeCompanyNameSearch(queryString string) ([]CompanyResult, error) {
-	params := "?SEARCHTYPE=&searchstring=*" + strings.ToUpper(queryString) + "*"
+func (s *scraper) scrapeCompanySearch(ctx context.Context, query string, opts SearchOptions) ([]CompanyResult, error) {
+	results := []CompanyResult{}
+	err := s.streamCompanySearch(ctx, query, opts, func(result CompanyResult) bool {
+		results = append(results, result)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// streamCompanySearch calls fn with each search result as it's read from the response, until fn returns false or
+// the limit is reached. Stopping early closes the response without reading the rest of it.
+func (s *scraper) streamCompanySearch(ctx context.Context, query string, opts SearchOptions, fn func(CompanyResult) bool) error {
+	params, err := opts.params(query)
+	if err != nil {
+		return err
+	}
 	reqURL := searchURL
 	if s.searchURL != "" {
 		reqURL = s.searchURL
 	}
-	node, err := postRequestToHTMLNode(reqURL + params)
+	body, err := s.lookupStream(ctx, reqURL+"?"+params.Encode())
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return htmlNodeToCompanyResults(node)
+	defer body.Close()
+	if opts.Limit > 0 {
+		next, count := fn, 0
+		fn = func(result CompanyResult) bool {
+			count++
+			return next(result) && count < opts.Limit
+		}
+	}
+	return scanSearchResults(ctx, body, fn)
 }
 
-func postRequestToHTMLNode(reqURL string) (*html.Node, error) {
-	req, err := http.NewRequest(http.MethodPost, reqURL, http.NoBody)
+// postRequest sends the request and reads the response body. When ctx is done, ctx.Err() is returned in place
+// of the transport or read error so callers can match context.Canceled and context.DeadlineExceeded.
+func (s *scraper) postRequest(ctx context.Context, reqURL string) ([]byte, error) {
+	respBody, err := s.openRequest(ctx, reqURL)
 	if err != nil {
 		return nil, err
 	}
-	req.Header = headers
-	resp, err := http.DefaultClient.Do(req)
+	defer respBody.Close()
+	body, err := io.ReadAll(respBody)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, unavailableError(err)
+	}
+	return body, nil
+}
+
+// bodySnippet reads the start of a failed response's body for an HTTPError, then drains the rest so the
+// connection can be reused
+func bodySnippet(resp *http.Response) string {
+	var snippet []byte
+	if body, err := decodeBody(io.NopCloser(resp.Body), resp.Header.Get("Content-Encoding")); err == nil {
+		snippet, _ = io.ReadAll(io.LimitReader(body, maxErrorBodySnippet))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return string(snippet)
+}
+
+// openRequest sends the request and returns the body of a 200 response, which the caller must close
+func (s *scraper) openRequest(ctx context.Context, reqURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, http.NoBody)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	// each request gets its own copy, so transports and middleware that modify it can't race with other requests
+	if s.headers != nil {
+		req.Header = s.headers.Clone()
+	} else {
+		req.Header = defaultHeaders()
+	}
+	httpClient := http.DefaultClient
+	if s.httpClient != nil {
+		httpClient = s.httpClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, unavailableError(err)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status + " Response from SAFER")
+		defer resp.Body.Close()
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			URL:        reqURL,
+			Body:       bodySnippet(resp),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return decodeBody(resp.Body, resp.Header.Get("Content-Encoding"))
+}
+
+// parseHTML parses a response body, returning ctx.Err() if the context finished first
+func parseHTML(ctx context.Context, body []byte) (*html.Node, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	node, err := htmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, parseError(err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	return htmlquery.Parse(resp.Body)
+	return node, nil
 }
@@ -0,0 +1,311 @@
+package safer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SearchMatch is how a search query is matched against carrier names
+type SearchMatch string
+
+// Search match modes
+const (
+	// MatchContains finds names containing the query anywhere, the default
+	MatchContains SearchMatch = "contains"
+	// MatchExact finds names equal to the query
+	MatchExact SearchMatch = "exact"
+	// MatchPrefix finds names starting with the query
+	MatchPrefix SearchMatch = "prefix"
+	// MatchSuffix finds names ending with the query
+	MatchSuffix SearchMatch = "suffix"
+)
+
+// SearchCase is how the case of a search query is handled before it's sent
+type SearchCase string
+
+// Search case policies
+const (
+	// CaseUpper uppercases the query to match the way SAFER stores names, the default
+	CaseUpper SearchCase = "upper"
+	// CasePreserve sends the query as given
+	CasePreserve SearchCase = "preserve"
+)
+
+// SearchOptions configures a company search. The zero value is the plain name search done by
+// SearchCompaniesByName. Only carrier names are searched: the SEARCHTYPE of SAFER's keyword search is always sent
+// empty, as the SAFER website's name search does, since no other value has a known meaning for it.
+type SearchOptions struct {
+	// Match is how the query is matched. Defaults to MatchContains.
+	Match SearchMatch
+	// Limit stops the search after this many results. Zero or less returns every result.
+	Limit int
+	// Case is how the query's case is handled. Defaults to CaseUpper.
+	Case SearchCase
+}
+
+// params returns the keyword search parameters for query
+func (o SearchOptions) params(query string) (url.Values, error) {
+	switch o.Case {
+	case "", CaseUpper:
+		query = strings.ToUpper(query)
+	case CasePreserve:
+	default:
+		return nil, fmt.Errorf("unknown search case %q", o.Case)
+	}
+	switch o.Match {
+	case "", MatchContains:
+		query = "*" + query + "*"
+	case MatchExact:
+	case MatchPrefix:
+		query = query + "*"
+	case MatchSuffix:
+		query = "*" + query
+	default:
+		return nil, fmt.Errorf("unknown search match %q", o.Match)
+	}
+	return url.Values{"SEARCHTYPE": {""}, "searchstring": {query}}, nil
+}
+
+// SearchCompanies - Search for carriers matching query with the given options, such as an exact or prefix match or
+// a result limit. The zero SearchOptions is the same search as SearchCompaniesByNameContext.
+func (c *Client) SearchCompanies(ctx context.Context, query string, opts SearchOptions) ([]CompanyResult, error) {
+	return c.scraper.scrapeCompanySearch(ctx, query, opts)
+}
+
+// SearchCompaniesFunc - Same as SearchCompanies but calls fn with each result as it's read from the response. Return
+// false from fn to stop early.
+func (c *Client) SearchCompaniesFunc(ctx context.Context, query string, opts SearchOptions, fn func(CompanyResult) bool) error {
+	return c.scraper.streamCompanySearch(ctx, query, opts, fn)
+}
+
+// SearchResultScanner reads the results of a SAFER company name search page one at a time with the HTML
+// tokenizer, without building the page's DOM or holding every result in memory. It's used like a bufio.Scanner:
+//
+//	scanner := safer.NewSearchResultScanner(r)
+//	for scanner.Scan() {
+//		result := scanner.Result()
+//	}
+//	if err := scanner.Err(); err != nil {
+//		...
+//	}
+type SearchResultScanner struct {
+	tokenizer *html.Tokenizer
+	result    CompanyResult
+	pending   bool // a result row has been started but not returned
+	err       error
+
+	// the page's markers before its first result, to classify a page without results
+	started  bool
+	inScript bool
+	inTitle  bool
+	markers  pageMarkers
+
+	// where the tokenizer is within the current result row
+	inName        bool
+	inLocationTD  bool
+	inLocationB   bool
+	locationFound bool
+	nameFound     bool
+	name          strings.Builder
+}
+
+// NewSearchResultScanner returns a scanner reading a search results page from r
+func NewSearchResultScanner(r io.Reader) *SearchResultScanner {
+	return &SearchResultScanner{tokenizer: html.NewTokenizer(r)}
+}
+
+// Scan advances to the next result, which is then available through Result. It returns false at the end of the
+// page or on a read error, which is then available through Err.
+func (s *SearchResultScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for {
+		switch s.tokenizer.Next() {
+		case html.ErrorToken:
+			if err := s.tokenizer.Err(); err != io.EOF {
+				s.err = err
+				return false
+			}
+			s.err = io.EOF
+			if s.finish() {
+				return true
+			}
+			if !s.started {
+				if err := s.markers.classify(); err != nil {
+					s.err = err
+				}
+			}
+			return false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := s.tokenizer.TagName()
+			switch string(name) {
+			case "tr":
+				if s.finish() {
+					return true
+				}
+			case "th":
+				if hasAttr && !s.pending && s.hasResultScope() {
+					s.startRow()
+				}
+			case "a":
+				if s.pending && !s.nameFound && !s.inName {
+					s.inName = true
+					s.name.Reset()
+					if href, ok := s.attr("href"); ok {
+						s.result.DOTNumber = parseDotFromSearchParams(href)
+					}
+				}
+			case "td":
+				if s.pending && !s.locationFound {
+					s.inLocationTD = true
+				}
+			case "b":
+				if s.inLocationTD {
+					s.inLocationB = true
+				}
+			case "script", "style":
+				if hasAttr && !s.started {
+					s.addMarkers(string(name))
+				}
+				s.inScript = true
+			case "title":
+				s.inTitle = true
+			default:
+				if hasAttr && !s.started {
+					s.addMarkers(string(name))
+				}
+			}
+		case html.EndTagToken:
+			name, _ := s.tokenizer.TagName()
+			switch string(name) {
+			case "tr", "table":
+				if s.finish() {
+					return true
+				}
+			case "a":
+				if s.inName {
+					s.inName = false
+					s.nameFound = true
+					s.result.Name = s.name.String()
+				}
+			case "td":
+				s.inLocationTD, s.inLocationB = false, false
+			case "b":
+				s.inLocationB = false
+			case "script", "style":
+				s.inScript = false
+			case "title":
+				s.inTitle = false
+			}
+		case html.TextToken:
+			switch {
+			case s.started || s.inScript:
+			case s.inTitle:
+				s.markers.title.Write(s.tokenizer.Text())
+			default:
+				s.markers.addText(string(s.tokenizer.Text()))
+			}
+			if s.inName {
+				s.name.Write(s.tokenizer.Text())
+			} else if s.inLocationB && !s.locationFound {
+				s.locationFound = true
+				s.result.Location = strings.TrimSpace(string(s.tokenizer.Text()))
+			}
+		}
+	}
+}
+
+// Result returns the result read by the last call to Scan
+func (s *SearchResultScanner) Result() CompanyResult {
+	return s.result
+}
+
+// Err returns the first error the scanner hit reading the page, or nil at the end of the page. A page without
+// results that is a request rejected, captcha or maintenance page returns an error matching ErrBlocked or
+// ErrMaintenance.
+func (s *SearchResultScanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+// hasResultScope reports whether the current tag is a result row's heading, which SAFER marks with scope="rpw"
+func (s *SearchResultScanner) hasResultScope() bool {
+	scope, ok := s.attr("scope")
+	return ok && strings.EqualFold(scope, "rpw")
+}
+
+// attr returns the value of the current tag's attribute
+func (s *SearchResultScanner) attr(key string) (string, bool) {
+	for {
+		k, v, more := s.tokenizer.TagAttr()
+		if string(k) == key {
+			return string(v), true
+		}
+		if !more {
+			return "", false
+		}
+	}
+}
+
+// addMarkers records the interstitial markers in the current tag's attributes
+func (s *SearchResultScanner) addMarkers(tag string) {
+	for {
+		k, v, more := s.tokenizer.TagAttr()
+		s.markers.element(tag, string(k), string(v))
+		if !more {
+			return
+		}
+	}
+}
+
+// startRow begins a new result row
+func (s *SearchResultScanner) startRow() {
+	s.result = CompanyResult{}
+	s.pending, s.started = true, true
+	s.inName, s.nameFound = false, false
+	s.inLocationTD, s.inLocationB, s.locationFound = false, false, false
+}
+
+// finish ends the current result row, reporting whether there was one to return
+func (s *SearchResultScanner) finish() bool {
+	if !s.pending {
+		return false
+	}
+	if s.inName {
+		s.result.Name = s.name.String()
+	}
+	s.pending, s.inName, s.inLocationTD, s.inLocationB = false, false, false, false
+	return true
+}
+
+// scanSearchResults calls fn with each result read from a response body until fn returns false
+func scanSearchResults(ctx context.Context, r io.Reader, fn func(CompanyResult) bool) error {
+	scanner := NewSearchResultScanner(r)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(scanner.Result()) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if errors.Is(err, ErrBlocked) || errors.Is(err, ErrMaintenance) {
+			return err
+		}
+		return unavailableError(err)
+	}
+	return ctx.Err()
+}
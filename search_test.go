@@ -0,0 +1,192 @@
+package safer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func TestSearchResultScanner(t *testing.T) {
+	tests := []struct {
+		name string
+		page []byte
+	}{
+		{"short", readTestData("./testdata/search-result-short.html")},
+		{"long", readTestData("./testdata/search-result.html")},
+		{"empty", []byte("<html><body></body></html>")},
+		{"truncated in a tag", readTestData("./testdata/search-result-short.html")[:3000]},
+		{"truncated in a row", readTestData("./testdata/search-result-short.html")[:3200]},
+	}
+	for _, tt := range tests {
+		node, err := htmlquery.Parse(bytes.NewReader(tt.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := htmlNodeToCompanyResults(node)
+
+		got := []CompanyResult{}
+		scanner := NewSearchResultScanner(bytes.NewReader(tt.page))
+		for scanner.Scan() {
+			got = append(got, scanner.Result())
+		}
+		if err := scanner.Err(); err != nil {
+			t.Errorf("%s: Err() = %v, want nil", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: results = %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestSearchResultScanner_ReadError(t *testing.T) {
+	page := readTestData("./testdata/search-result-short.html")
+	scanner := NewSearchResultScanner(io.MultiReader(bytes.NewReader(page[:3200]), errReader{}))
+	for scanner.Scan() {
+	}
+	if err := scanner.Err(); err == nil || err.Error() != "connection reset" {
+		t.Errorf("Err() = %v, want connection reset", err)
+	}
+	if scanner.Scan() {
+		t.Error("Scan() = true after an error, want false")
+	}
+}
+
+func TestClient_SearchCompaniesByNameFunc(t *testing.T) {
+	page := readTestData("./testdata/search-result.html")
+	closed := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// send the first half of the page and hold the rest until the client goes away
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(page[:len(page)/2])
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+			close(closed)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	var got []CompanyResult
+	err := c.SearchCompaniesByNameFunc(context.Background(), "schneider", func(result CompanyResult) bool {
+		got = append(got, result)
+		return len(got) < 3
+	})
+	if err != nil {
+		t.Fatalf("SearchCompaniesByNameFunc should return no error, but got %v", err)
+	}
+	want := []CompanyResult{
+		{Name: "A- SCHNEIDER CONSTRUCTION LLC", DOTNumber: "1261876", Location: "CADOTT, WI"},
+		{Name: "AARON DUANE SCHNEIDER", DOTNumber: "2563009", Location: "BRODHEAD, WI"},
+		{Name: "AARON SCHNEIDER", DOTNumber: "3456265", Location: "STANLEY, WI"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchCompaniesByNameFunc() = %v, want %v", got, want)
+	}
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Error("SearchCompaniesByNameFunc should close the response after stopping early")
+	}
+}
+
+func TestClient_SearchCompaniesByNameFunc_Canceled(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	c := &Client{scraper: scraper{searchURL: ts.URL + "/search"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := c.SearchCompaniesByNameFunc(ctx, "", func(CompanyResult) bool {
+		calls++
+		cancel()
+		return true
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("SearchCompaniesByNameFunc() error = %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Errorf("SearchCompaniesByNameFunc() called fn %d times, want 1", calls)
+	}
+}
+
+func BenchmarkSearchResultScanner_484Results(b *testing.B) {
+	page := readTestData("./testdata/search-result.html")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		scanner := NewSearchResultScanner(bytes.NewReader(page))
+		for scanner.Scan() {
+		}
+	}
+}
+
+func TestSearchOptions_params(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    SearchOptions
+		want    url.Values
+		wantErr bool
+	}{
+		{"default", SearchOptions{}, url.Values{"SEARCHTYPE": {""}, "searchstring": {"*SCHNEIDER TR*"}}, false},
+		{"contains", SearchOptions{Match: MatchContains, Case: CaseUpper}, url.Values{"SEARCHTYPE": {""}, "searchstring": {"*SCHNEIDER TR*"}}, false},
+		{"exact", SearchOptions{Match: MatchExact}, url.Values{"SEARCHTYPE": {""}, "searchstring": {"SCHNEIDER TR"}}, false},
+		{"prefix", SearchOptions{Match: MatchPrefix}, url.Values{"SEARCHTYPE": {""}, "searchstring": {"SCHNEIDER TR*"}}, false},
+		{"suffix", SearchOptions{Match: MatchSuffix}, url.Values{"SEARCHTYPE": {""}, "searchstring": {"*SCHNEIDER TR"}}, false},
+		{"preserve case", SearchOptions{Case: CasePreserve}, url.Values{"SEARCHTYPE": {""}, "searchstring": {"*Schneider tr*"}}, false},
+		{"unknown match", SearchOptions{Match: "fuzzy"}, nil, true},
+		{"unknown case", SearchOptions{Case: "lower"}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := tt.opts.params("Schneider tr")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: params() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: params() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClient_SearchCompanies(t *testing.T) {
+	var query url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(readTestData("./testdata/search-result.html"))
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	results, err := c.SearchCompanies(context.Background(), "schneider & sons #1", SearchOptions{Match: MatchPrefix, Limit: 2})
+	if err != nil {
+		t.Fatalf("SearchCompanies should return no error, but got %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("SearchCompanies() returned %d results, want 2", len(results))
+	}
+	want := url.Values{"SEARCHTYPE": {""}, "searchstring": {"SCHNEIDER & SONS #1*"}}
+	if !reflect.DeepEqual(query, want) {
+		t.Errorf("SearchCompanies() sent query %v, want %v", query, want)
+	}
+
+	if _, err := c.SearchCompanies(context.Background(), "schneider", SearchOptions{Match: "fuzzy"}); err == nil {
+		t.Error("SearchCompanies should return an error for an unknown match mode")
+	}
+}
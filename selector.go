@@ -0,0 +1,131 @@
+package safer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// simplePathRegex matches paths made only of child steps with an optional position, such as "/td[2]/text()"
+var simplePathRegex = regexp.MustCompile(`^(/([a-z][a-z0-9]*|text\(\))(\[[1-9][0-9]*\])?)+$`)
+
+// selector is a precompiled xpath. Most selectors in a Mapping are simple child steps, which are walked directly
+// on the html.Node tree without the allocations of an xpath navigator. Anything else is evaluated with xpath.
+type selector struct {
+	steps []selectorStep
+	expr  *xpath.Expr
+}
+
+// selectorStep matches the children of a node with the tag name, or text children if text is set. A non-zero
+// position keeps only the position'th (1-based) match.
+type selectorStep struct {
+	name     string
+	text     bool
+	position int
+}
+
+func compileSelector(path string) (*selector, error) {
+	if !simplePathRegex.MatchString(path) {
+		expr, err := xpath.Compile(path)
+		if err != nil {
+			return nil, err
+		}
+		return &selector{expr: expr}, nil
+	}
+	var steps []selectorStep
+	for _, part := range strings.Split(path[1:], "/") {
+		var step selectorStep
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			step.position, _ = strconv.Atoi(part[i+1 : len(part)-1])
+			part = part[:i]
+		}
+		if part == "text()" {
+			step.text = true
+		} else {
+			step.name = part
+		}
+		steps = append(steps, step)
+	}
+	return &selector{steps: steps}, nil
+}
+
+func mustCompileSelector(path string) *selector {
+	s, err := compileSelector(path)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// find returns every node the selector matches under node, in document order
+func (s *selector) find(node *html.Node) []*html.Node {
+	if node == nil {
+		return nil
+	}
+	if s.expr != nil {
+		return htmlquery.QuerySelectorAll(node, s.expr)
+	}
+	var out []*html.Node
+	s.walk(node, s.steps, func(n *html.Node) bool {
+		out = append(out, n)
+		return true
+	})
+	return out
+}
+
+// findOne returns the first node the selector matches under node, or nil
+func (s *selector) findOne(node *html.Node) *html.Node {
+	if node == nil {
+		return nil
+	}
+	if s.expr != nil {
+		return htmlquery.QuerySelector(node, s.expr)
+	}
+	var found *html.Node
+	s.walk(node, s.steps, func(n *html.Node) bool {
+		found = n
+		return false
+	})
+	return found
+}
+
+// text returns the trimmed text of the first node the selector matches, or "" if there isn't one
+func (s *selector) text(node *html.Node) string {
+	if found := s.findOne(node); found != nil {
+		return strings.TrimSpace(found.Data)
+	}
+	return ""
+}
+
+// walk calls fn with each match of steps under node until fn returns false. It reports whether to keep going.
+func (s *selector) walk(node *html.Node, steps []selectorStep, fn func(*html.Node) bool) bool {
+	step := steps[0]
+	position := 0
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if step.text {
+			if child.Type != html.TextNode {
+				continue
+			}
+		} else if child.Type != html.ElementNode || child.Data != step.name {
+			continue
+		}
+		position++
+		if step.position > 0 && position != step.position {
+			continue
+		}
+		var more bool
+		if len(steps) == 1 {
+			more = fn(child)
+		} else {
+			more = s.walk(child, steps[1:], fn)
+		}
+		if !more || step.position > 0 {
+			return more
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package safer
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func TestSelector_MatchesXpath(t *testing.T) {
+	node, err := htmlquery.Parse(bytes.NewReader(readTestData("./testdata/snapshot-basic.html")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := htmlquery.FindOne(node, srcTableXpath)
+	tests := []struct {
+		name   string
+		path   string
+		simple bool
+	}{
+		{"positions", tableUSCrashXpath + "/tr[2]/td[1]/text()", true},
+		{"every match", tableGeneralInfoXpath + "/tr/th", true},
+		{"text children", tableGeneralInfoXpath + "/tr[3]/td/text()", true},
+		{"no match", "/center[20]/table", true},
+		{"descendants", safetyRatingHeadingXpath, false},
+		{"union", "/center[1]/table/tbody/tr[3]/td/text() | /center[1]/table/tbody/tr[3]/td/font/b/text()", false},
+	}
+	for _, tt := range tests {
+		sel, err := compileSelector(tt.path)
+		if err != nil {
+			t.Fatalf("%s: compileSelector() error = %v", tt.name, err)
+		}
+		if simple := sel.expr == nil; simple != tt.simple {
+			t.Errorf("%s: compileSelector() simple = %v, want %v", tt.name, simple, tt.simple)
+		}
+		want := htmlquery.Find(src, tt.path)
+		if got := sel.find(src); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: find() = %v, want %v", tt.name, got, want)
+		}
+		if got := sel.findOne(src); got != htmlquery.FindOne(src, tt.path) {
+			t.Errorf("%s: findOne() = %v, want %v", tt.name, got, htmlquery.FindOne(src, tt.path))
+		}
+	}
+}
+
+func TestSelector_Invalid(t *testing.T) {
+	if _, err := compileSelector("/td["); err == nil {
+		t.Error("compileSelector should return an error")
+	}
+}
@@ -0,0 +1,229 @@
+package safer
+
+import "strings"
+
+// OperatingStatus is a stable code for the operating status shown on the snapshot. The page text, which may
+// include details such as "AUTHORIZED FOR Property", is kept in CompanySnapshot.OperatingStatusRaw.
+type OperatingStatus string
+
+// Known operating statuses
+const (
+	OperatingStatusActive        OperatingStatus = "active"
+	OperatingStatusAuthorized    OperatingStatus = "authorized"
+	OperatingStatusNotAuthorized OperatingStatus = "not_authorized"
+	OperatingStatusOutOfService  OperatingStatus = "out_of_service"
+	OperatingStatusInactive      OperatingStatus = "inactive"
+	// OperatingStatusOther is used when the page text isn't a known status
+	OperatingStatusOther OperatingStatus = "other"
+)
+
+// EntityType is a stable code for one of the entity types listed on the snapshot
+type EntityType string
+
+// Known entity types
+const (
+	EntityTypeCarrier                     EntityType = "carrier"
+	EntityTypeBroker                      EntityType = "broker"
+	EntityTypeShipper                     EntityType = "shipper"
+	EntityTypeFreightForwarder            EntityType = "freight_forwarder"
+	EntityTypeCargoTank                   EntityType = "cargo_tank"
+	EntityTypeIntermodalEquipmentProvider EntityType = "intermodal_equipment_provider"
+	EntityTypeRegistrant                  EntityType = "registrant"
+)
+
+// OperationClassification is a stable code for one of the checked operation classifications
+type OperationClassification string
+
+// Known operation classifications
+const (
+	OperationClassificationAuthorizedForHire           OperationClassification = "authorized_for_hire"
+	OperationClassificationExemptForHire               OperationClassification = "exempt_for_hire"
+	OperationClassificationPrivateProperty             OperationClassification = "private_property"
+	OperationClassificationPrivatePassengerBusiness    OperationClassification = "private_passenger_business"
+	OperationClassificationPrivatePassengerNonBusiness OperationClassification = "private_passenger_non_business"
+	OperationClassificationMigrant                     OperationClassification = "migrant"
+	OperationClassificationUSMail                      OperationClassification = "us_mail"
+	OperationClassificationFederalGovernment           OperationClassification = "federal_government"
+	OperationClassificationStateGovernment             OperationClassification = "state_government"
+	OperationClassificationLocalGovernment             OperationClassification = "local_government"
+	OperationClassificationIndianNation                OperationClassification = "indian_nation"
+)
+
+// CarrierOperation is a stable code for one of the checked carrier operations
+type CarrierOperation string
+
+// Known carrier operations
+const (
+	CarrierOperationInterstate          CarrierOperation = "interstate"
+	CarrierOperationIntrastateHazmat    CarrierOperation = "intrastate_hazmat"
+	CarrierOperationIntrastateNonHazmat CarrierOperation = "intrastate_non_hazmat"
+)
+
+// Cargo is a stable code for one of the checked cargo carried types
+type Cargo string
+
+// Known cargo types
+const (
+	CargoGeneralFreight           Cargo = "general_freight"
+	CargoHouseholdGoods           Cargo = "household_goods"
+	CargoMetal                    Cargo = "metal"
+	CargoMotorVehicles            Cargo = "motor_vehicles"
+	CargoDriveTowAway             Cargo = "drive_tow_away"
+	CargoLogsPolesBeamsLumber     Cargo = "logs_poles_beams_lumber"
+	CargoBuildingMaterials        Cargo = "building_materials"
+	CargoMobileHomes              Cargo = "mobile_homes"
+	CargoMachineryLargeObjects    Cargo = "machinery_large_objects"
+	CargoFreshProduce             Cargo = "fresh_produce"
+	CargoLiquidsGases             Cargo = "liquids_gases"
+	CargoIntermodalContainers     Cargo = "intermodal_containers"
+	CargoPassengers               Cargo = "passengers"
+	CargoOilfieldEquipment        Cargo = "oilfield_equipment"
+	CargoLivestock                Cargo = "livestock"
+	CargoGrainFeedHay             Cargo = "grain_feed_hay"
+	CargoCoalCoke                 Cargo = "coal_coke"
+	CargoMeat                     Cargo = "meat"
+	CargoGarbageRefuse            Cargo = "garbage_refuse"
+	CargoUSMail                   Cargo = "us_mail"
+	CargoChemicals                Cargo = "chemicals"
+	CargoCommoditiesDryBulk       Cargo = "commodities_dry_bulk"
+	CargoRefrigeratedFood         Cargo = "refrigerated_food"
+	CargoBeverages                Cargo = "beverages"
+	CargoPaperProducts            Cargo = "paper_products"
+	CargoUtilities                Cargo = "utilities"
+	CargoAgriculturalFarmSupplies Cargo = "agricultural_farm_supplies"
+	CargoConstruction             Cargo = "construction"
+	CargoWaterWell                Cargo = "water_well"
+)
+
+// Rating is a stable code for the carrier's safety rating. The page text is kept in SafetyRating.RatingRaw.
+type Rating string
+
+// Known safety ratings
+const (
+	RatingSatisfactory   Rating = "satisfactory"
+	RatingConditional    Rating = "conditional"
+	RatingUnsatisfactory Rating = "unsatisfactory"
+	RatingNotRated       Rating = "not_rated"
+	// RatingOther is used when the page text isn't a known rating
+	RatingOther Rating = "other"
+)
+
+// vocabularies map page text, normalized with vocabularyKey, to its code
+var (
+	entityTypes = map[string]EntityType{
+		"CARRIER":                     EntityTypeCarrier,
+		"BROKER":                      EntityTypeBroker,
+		"SHIPPER":                     EntityTypeShipper,
+		"FREIGHTFORWARDER":            EntityTypeFreightForwarder,
+		"CARGOTANK":                   EntityTypeCargoTank,
+		"IEP":                         EntityTypeIntermodalEquipmentProvider,
+		"INTERMODALEQUIPMENTPROVIDER": EntityTypeIntermodalEquipmentProvider,
+		"REGISTRANT":                  EntityTypeRegistrant,
+	}
+	operationClassifications = map[string]OperationClassification{
+		"AUTH.FORHIRE":             OperationClassificationAuthorizedForHire,
+		"EXEMPTFORHIRE":            OperationClassificationExemptForHire,
+		"PRIVATE(PROPERTY)":        OperationClassificationPrivateProperty,
+		"PRIV.PASS.(BUSINESS)":     OperationClassificationPrivatePassengerBusiness,
+		"PRIV.PASS.(NON-BUSINESS)": OperationClassificationPrivatePassengerNonBusiness,
+		"MIGRANT":                  OperationClassificationMigrant,
+		"U.S.MAIL":                 OperationClassificationUSMail,
+		"FED.GOV'T":                OperationClassificationFederalGovernment,
+		"STATEGOV'T":               OperationClassificationStateGovernment,
+		"LOCALGOV'T":               OperationClassificationLocalGovernment,
+		"INDIANNATION":             OperationClassificationIndianNation,
+	}
+	carrierOperations = map[string]CarrierOperation{
+		"INTERSTATE":             CarrierOperationInterstate,
+		"INTRASTATEONLY(HM)":     CarrierOperationIntrastateHazmat,
+		"INTRASTATEONLY(NON-HM)": CarrierOperationIntrastateNonHazmat,
+	}
+	cargoTypes = map[string]Cargo{
+		"GENERALFREIGHT":            CargoGeneralFreight,
+		"HOUSEHOLDGOODS":            CargoHouseholdGoods,
+		"METAL:SHEETS,COILS,ROLLS":  CargoMetal,
+		"MOTORVEHICLES":             CargoMotorVehicles,
+		"DRIVE/TOWAWAY":             CargoDriveTowAway,
+		"LOGS,POLES,BEAMS,LUMBER":   CargoLogsPolesBeamsLumber,
+		"BUILDINGMATERIALS":         CargoBuildingMaterials,
+		"MOBILEHOMES":               CargoMobileHomes,
+		"MACHINERY,LARGEOBJECTS":    CargoMachineryLargeObjects,
+		"FRESHPRODUCE":              CargoFreshProduce,
+		"LIQUIDS/GASES":             CargoLiquidsGases,
+		"INTERMODALCONT.":           CargoIntermodalContainers,
+		"PASSENGERS":                CargoPassengers,
+		"OILFIELDEQUIPMENT":         CargoOilfieldEquipment,
+		"LIVESTOCK":                 CargoLivestock,
+		"GRAIN,FEED,HAY":            CargoGrainFeedHay,
+		"COAL/COKE":                 CargoCoalCoke,
+		"MEAT":                      CargoMeat,
+		"GARBAGE/REFUSE":            CargoGarbageRefuse,
+		"USMAIL":                    CargoUSMail,
+		"CHEMICALS":                 CargoChemicals,
+		"COMMODITIESDRYBULK":        CargoCommoditiesDryBulk,
+		"REFRIGERATEDFOOD":          CargoRefrigeratedFood,
+		"BEVERAGES":                 CargoBeverages,
+		"PAPERPRODUCTS":             CargoPaperProducts,
+		"UTILITIES":                 CargoUtilities,
+		"AGRICULTURAL/FARMSUPPLIES": CargoAgriculturalFarmSupplies,
+		"CONSTRUCTION":              CargoConstruction,
+		"WATERWELL":                 CargoWaterWell,
+	}
+	ratings = map[string]Rating{
+		"SATISFACTORY":   RatingSatisfactory,
+		"CONDITIONAL":    RatingConditional,
+		"UNSATISFACTORY": RatingUnsatisfactory,
+		"NONE":           RatingNotRated,
+		"NOTRATED":       RatingNotRated,
+	}
+)
+
+// vocabularyKey normalizes page text for vocabulary lookups, ignoring case and whitespace
+func vocabularyKey(text string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(text), ""))
+}
+
+func parseOperatingStatus(text string) OperatingStatus {
+	key := strings.ToUpper(normalizeSpace(text))
+	switch {
+	case key == "":
+		return ""
+	case strings.HasPrefix(key, "NOT AUTHORIZED"):
+		return OperatingStatusNotAuthorized
+	case strings.HasPrefix(key, "AUTHORIZED"):
+		return OperatingStatusAuthorized
+	case key == "ACTIVE":
+		return OperatingStatusActive
+	case strings.Contains(key, "OUT-OF-SERVICE") || strings.Contains(key, "OUT OF SERVICE"):
+		return OperatingStatusOutOfService
+	case strings.HasPrefix(key, "INACTIVE"):
+		return OperatingStatusInactive
+	}
+	return OperatingStatusOther
+}
+
+// parseEntityTypes splits a combined entity type such as "CARRIER/CARGO TANK/BROKER"
+func parseEntityTypes(text string) (known []EntityType, other []string) {
+	for _, part := range strings.Split(text, "/") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if entityType, ok := entityTypes[vocabularyKey(part)]; ok {
+			known = append(known, entityType)
+		} else {
+			other = append(other, part)
+		}
+	}
+	return
+}
+
+func parseRating(text string) Rating {
+	if text == "" {
+		return ""
+	}
+	if rating, ok := ratings[vocabularyKey(text)]; ok {
+		return rating
+	}
+	return RatingOther
+}
@@ -0,0 +1,117 @@
+package safer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func Test_parseOperatingStatus(t *testing.T) {
+	tests := []struct {
+		text string
+		want OperatingStatus
+	}{
+		{text: "AUTHORIZED", want: OperatingStatusAuthorized},
+		{text: "AUTHORIZED FOR Property", want: OperatingStatusAuthorized},
+		{text: "NOT AUTHORIZED", want: OperatingStatusNotAuthorized},
+		{text: "ACTIVE", want: OperatingStatusActive},
+		{text: "OUT-OF-SERVICE", want: OperatingStatusOutOfService},
+		{text: "INACTIVE USDOT NUMBER", want: OperatingStatusInactive},
+		{text: "PENDING", want: OperatingStatusOther},
+		{text: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := parseOperatingStatus(tt.text); got != tt.want {
+				t.Errorf("parseOperatingStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseEntityTypes(t *testing.T) {
+	known, other := parseEntityTypes("CARRIER/CARGO TANK/BROKER/SPACE SHIP")
+	if want := []EntityType{EntityTypeCarrier, EntityTypeCargoTank, EntityTypeBroker}; !reflect.DeepEqual(known, want) {
+		t.Errorf("parseEntityTypes() known = %v, want %v", known, want)
+	}
+	if want := []string{"SPACE SHIP"}; !reflect.DeepEqual(other, want) {
+		t.Errorf("parseEntityTypes() other = %v, want %v", other, want)
+	}
+	if known, other := parseEntityTypes(""); known != nil || other != nil {
+		t.Errorf("parseEntityTypes(\"\") = %v, %v, want nil, nil", known, other)
+	}
+}
+
+func Test_parseRating(t *testing.T) {
+	tests := []struct {
+		text string
+		want Rating
+	}{
+		{text: "Satisfactory", want: RatingSatisfactory},
+		{text: "CONDITIONAL", want: RatingConditional},
+		{text: "Unsatisfactory", want: RatingUnsatisfactory},
+		{text: "None", want: RatingNotRated},
+		{text: "Excellent", want: RatingOther},
+		{text: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := parseRating(tt.text); got != tt.want {
+				t.Errorf("parseRating() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// every checkbox label on the page, checked or not, should map to a known code
+func TestVocabulary_CoversSnapshotLabels(t *testing.T) {
+	root, err := htmlquery.LoadDoc("./testdata/snapshot-basic.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := htmlquery.FindOne(root, srcTableXpath+tableGeneralInfoXpath)
+	grids := []struct {
+		name  string
+		path  string
+		vocab func(string) bool
+	}{
+		{"operation classification", "/tr[14]", func(s string) bool { _, ok := operationClassifications[vocabularyKey(s)]; return ok }},
+		{"carrier operation", "/tr[16]", func(s string) bool { _, ok := carrierOperations[vocabularyKey(s)]; return ok }},
+		{"cargo carried", "/tr[19]", func(s string) bool { _, ok := cargoTypes[vocabularyKey(s)]; return ok }},
+	}
+	for _, grid := range grids {
+		labels := getNodeTexts(node, grid.path+"/td/table/tbody/tr[2]/td/table/tbody/tr/td/font/text()")
+		if len(labels) == 0 {
+			t.Errorf("%s: no labels found", grid.name)
+		}
+		for _, label := range labels {
+			if !grid.vocab(label) {
+				t.Errorf("%s: label %q is not in the vocabulary", grid.name, label)
+			}
+		}
+	}
+}
+
+func TestVocabulary_JSON(t *testing.T) {
+	snapshot := CompanySnapshot{
+		OperatingStatus: OperatingStatusNotAuthorized,
+		EntityType:      []EntityType{EntityTypeCarrier},
+		CargoCarried:    []Cargo{CargoGeneralFreight},
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["operating_status"] != "not_authorized" {
+		t.Errorf("operating_status = %v, want %v", got["operating_status"], "not_authorized")
+	}
+	if !reflect.DeepEqual(got["cargo_carried"], []interface{}{"general_freight"}) {
+		t.Errorf("cargo_carried = %v, want %v", got["cargo_carried"], []string{"general_freight"})
+	}
+}
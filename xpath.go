@@ -1,26 +1,26 @@
 package safer
 
 import (
+	"io"
 	"strings"
 
 	"github.com/antchfx/htmlquery"
 	"golang.org/x/net/html"
 )
 
-// company snapshot xpath constants
+// company snapshot xpath constants, used by DefaultMapping
 const (
-	snapshotNotFoundXpath      = "/html/head/title[text()='SAFER Web - Company Snapshot RECORD NOT FOUND' or text()='SAFER Web - Company Snapshot RECORD INACTIVE']"
+	snapshotNotFoundXpath      = "/html/head/title[text()='SAFER Web - Company Snapshot RECORD NOT FOUND']"
+	snapshotInactiveXpath      = "/html/head/title[text()='SAFER Web - Company Snapshot RECORD INACTIVE']"
 	srcTableXpath              = "/html/body/p/table/tbody/tr[2]/td/table/tbody/tr[2]/td"
-	latestUpdateDateXpath      = "/table/tbody/tr[3]/td/font/b[3]/font/text()"
+	latestUpdateXpath          = "/table/tbody/tr[3]/td/font/b[3]/font"
 	tableGeneralInfoXpath      = "/center[1]/table/tbody"
-	tableOperationClassXpath   = "/tr[14]/td/table/tbody/tr[2]/td/table/tbody/tr[.//td[@class='queryfield']/text() = 'X']"
-	tableCarrierOpXpath        = "/tr[16]/td/table/tbody/tr[2]/td/table/tbody/tr[.//td[@class='queryfield']/text() = 'X']/td/font/text()"
-	tableCargoCarriedXpath     = "/tr[19]/td/table/tbody/tr[2]/td/table/tbody/tr[.//td[@class='queryfield']/text() = 'X']"
-	tableUSInspectionXpath     = "/center[3]/table/tbody/tr"
-	tableUSCrashXpath          = "/center[4]/table/tbody/tr[2]/td/text()"
-	tableCanadaInspectionXpath = "/center[6]/table/tbody/tr"
-	tableCanadaCrashXpath      = "/center[7]/table/tbody/tr[2]/td/text()"
-	tableSafetyRatingXpath     = "/center[9]/table/tbody/tr"
+	tableUSInspectionXpath     = "/center[3]/table/tbody"
+	tableUSCrashXpath          = "/center[4]/table/tbody"
+	tableCanadaInspectionXpath = "/center[6]/table/tbody"
+	tableCanadaCrashXpath      = "/center[7]/table/tbody"
+	tableSafetyRatingXpath     = "/center[9]/table/tbody"
+	safetyRatingHeadingXpath   = ".//a[@name='Safety']"
 )
 
 // company search xpath constants
@@ -28,141 +28,137 @@ const (
 	companyResultXpath = "/html/body/table[3]/tbody/tr[.//*[@scope='rpw']]"
 )
 
+// ParseCompanySnapshot parses a SAFER company snapshot page read from r, such as an archived page or one fetched
+// outside of Client. To parse a []byte, pass bytes.NewReader(b).
+//
+// Returns ErrCompanyNotFound or ErrCompanyInactive for SAFER's record not found or record inactive page,
+// ErrBlocked or ErrMaintenance for a request rejected, captcha or maintenance page, and ErrParse when r can't be
+// read as HTML.
+func ParseCompanySnapshot(r io.Reader) (*CompanySnapshot, error) {
+	node, err := htmlquery.Parse(r)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return htmlNodeToCompanySnapshot(node)
+}
+
+// ParseCompanySnapshotNode is ParseCompanySnapshot for a page that has already been parsed into an html.Node
+func ParseCompanySnapshotNode(root *html.Node) (*CompanySnapshot, error) {
+	return htmlNodeToCompanySnapshot(root)
+}
+
+// ParseSearchResults parses a SAFER company name search results page read from r. To parse a []byte, pass
+// bytes.NewReader(b), and to read the results one at a time, use a SearchResultScanner instead.
+//
+// A page without results returns an empty slice, unless it's a request rejected, captcha or maintenance page,
+// which returns ErrBlocked or ErrMaintenance.
+func ParseSearchResults(r io.Reader) ([]CompanyResult, error) {
+	results := []CompanyResult{}
+	scanner := NewSearchResultScanner(r)
+	for scanner.Scan() {
+		results = append(results, scanner.Result())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ParseSearchResultsNode is ParseSearchResults for a page that has already been parsed into an html.Node
+func ParseSearchResultsNode(root *html.Node) ([]CompanyResult, error) {
+	return htmlNodeToCompanyResults(root)
+}
+
 func htmlNodeToCompanySnapshot(root *html.Node) (*CompanySnapshot, error) {
-	if found := htmlquery.Find(root, snapshotNotFoundXpath); found != nil && len(found) > 0 {
+	snapshot, _, err := parseCompanySnapshot(root, ParseOptions{})
+	return snapshot, err
+}
+
+func (p *snapshotParser) parse(root *html.Node) (*CompanySnapshot, error) {
+	m := p.mapping
+	if m.notFound.findOne(root) != nil {
 		return nil, ErrCompanyNotFound
 	}
+	if m.inactive.findOne(root) != nil {
+		return nil, ErrCompanyInactive
+	}
 	snapshot := new(CompanySnapshot)
-	if srcNode := htmlquery.FindOne(root, srcTableXpath); srcNode != nil {
-		snapshot.LatestUpdateDate = parseDate(getNodeText(srcNode, latestUpdateDateXpath))
-		// general info
-		if node := htmlquery.FindOne(srcNode, tableGeneralInfoXpath); node != nil {
-			snapshot.EntityType = getNodeText(node, "/tr[2]/td/text()")
-			if tr3 := htmlquery.FindOne(node, "/tr[3]"); tr3 != nil {
-				snapshot.OutOfServiceDate = parseDate(getNodeText(tr3, "/td[2]/text()"))
-				snapshot.OperatingStatus = getNodeText(tr3, "/td[1]/text()")
-				if snapshot.OperatingStatus == "" {
-					// out-of-service is bolded and not caught by the previous xpath
-					snapshot.OperatingStatus = getNodeText(tr3, "/td[1]/font/b/text()")
-				}
-			}
-			snapshot.LegalName = getNodeText(node, "/tr[4]/td/text()")
-			snapshot.DBAName = getNodeText(node, "/tr[5]/td/text()")
-			snapshot.PhysicalAddress = parseAddress(getNodeTexts(node, "/tr[6]/td/text()")...)
-			snapshot.Phone = getNodeText(node, "/tr[7]/td/text()")
-			snapshot.MailingAddress = parseAddress(getNodeTexts(node, "/tr[8]/td/text()")...)
-			if tr9 := htmlquery.FindOne(node, "/tr[9]"); tr9 != nil {
-				snapshot.DOTNumber = getNodeText(tr9, "/td[1]/text()")
-				snapshot.StateCarrierID = getNodeText(tr9, "/td[2]/text()")
-			}
-			if tr10 := htmlquery.FindOne(node, "/tr[10]"); tr10 != nil {
-				snapshot.MCMXFFNumbers = getNodeTexts(tr10, "/td[1]/a/text()")
-				snapshot.DUNSNumber = getNodeText(tr10, "/td[2]/text()")
-				if snapshot.DUNSNumber == "--" {
-					snapshot.DUNSNumber = ""
-				}
-			}
-			if tr11 := htmlquery.FindOne(node, "/tr[11]"); tr11 != nil {
-				snapshot.PowerUnits = parseInt(getNodeText(tr11, "/td[1]/text()"))
-				snapshot.Drivers = parseInt(getNodeText(tr11, "/td[2]/font/b/text()"))
-			}
-			if tr12 := htmlquery.FindOne(node, "/tr[12]"); tr12 != nil {
-				snapshot.MCS150FormDate = parseDate(getNodeText(tr12, "/td[1]/text()"))
-				snapshot.MCS150Mileage, snapshot.MCS150Year = parseMCS150MileageYear(getNodeText(tr12, "/td[2]/font/b/text()"))
-			}
-			// carrier classification
-			for _, classNode := range htmlquery.Find(node, tableOperationClassXpath) {
-				classification := getNodeText(classNode, "/td/font/text()")
-				if classification == "" {
-					// optional extra classifications (not all will have this)
-					classification = getNodeText(classNode, "/td[2]/text()")
-				}
-				if classification != "" {
-					snapshot.OperationClassification = append(snapshot.OperationClassification, classification)
-				}
-			}
-			// carrier operation
-			operations := getNodeTexts(node, tableCarrierOpXpath)
-			for _, op := range operations {
-				snapshot.CarrierOperation = append(snapshot.CarrierOperation, op)
-			}
-			// cargo carried
-			for _, cargoNode := range htmlquery.Find(node, tableCargoCarriedXpath) {
-				cargo := getNodeText(cargoNode, "/td/font/text()")
-				if cargo == "" {
-					// optional extra classifications (not all will have this)
-					cargo = getNodeText(cargoNode, "/td[2]/text()")
-				}
-				if cargo != "" {
-					snapshot.CargoCarried = append(snapshot.CargoCarried, cargo)
-				}
-			}
+	srcNode := m.source.findOne(root)
+	if srcNode == nil {
+		if err := classifyPage(root); err != nil {
+			return nil, err
 		}
-		// us inspections
-		if nodes := htmlquery.Find(srcNode, tableUSInspectionXpath); nodes != nil {
-			// tr[2]
-			snapshot.USVehicleInspections.Inspections = parseInt(getNodeText(nodes[1], "/td[1]/text()"))
-			snapshot.USDriverInspections.Inspections = parseInt(getNodeText(nodes[1], "/td[2]/text()"))
-			snapshot.USHazmatInspections.Inspections = parseInt(getNodeText(nodes[1], "/td[3]/text()"))
-			snapshot.USIEPInspections.Inspections = parseInt(getNodeText(nodes[1], "/td[4]/text()"))
-			// tr[3]
-			snapshot.USVehicleInspections.OutOfService = parseInt(getNodeText(nodes[2], "/td[1]/text()"))
-			snapshot.USDriverInspections.OutOfService = parseInt(getNodeText(nodes[2], "/td[2]/text()"))
-			snapshot.USHazmatInspections.OutOfService = parseInt(getNodeText(nodes[2], "/td[3]/text()"))
-			snapshot.USIEPInspections.OutOfService = parseInt(getNodeText(nodes[2], "/td[4]/text()"))
-			// tr[4]
-			snapshot.USVehicleInspections.OutOfServicePct = parsePctToFloat32(getNodeText(nodes[3], "/td[1]/text()"))
-			snapshot.USDriverInspections.OutOfServicePct = parsePctToFloat32(getNodeText(nodes[3], "/td[2]/text()"))
-			snapshot.USHazmatInspections.OutOfServicePct = parsePctToFloat32(getNodeText(nodes[3], "/td[3]/text()"))
-			snapshot.USIEPInspections.OutOfServicePct = parsePctToFloat32(getNodeText(nodes[3], "/td[4]/text()"))
-			// tr[5]
-			snapshot.USVehicleInspections.NationalAverage = parsePctToFloat32(getNodeText(nodes[4], "/td[1]/font/text()"))
-			snapshot.USDriverInspections.NationalAverage = parsePctToFloat32(getNodeText(nodes[4], "/td[2]/font/text()"))
-			snapshot.USHazmatInspections.NationalAverage = parsePctToFloat32(getNodeText(nodes[4], "/td[3]/font/text()"))
-			snapshot.USIEPInspections.NationalAverage = parsePctToFloat32(getNodeText(nodes[4], "/td[4]/font/text()"))
+		p.missingSection(SectionSource)
+		return snapshot, nil
+	}
+	for i := range m.sections {
+		section := &m.sections[i]
+		node := section.path.findOne(srcNode)
+		if node == nil {
+			if section.optionalIf == nil || section.optionalIf.findOne(srcNode) == nil {
+				p.missingSection(section.name)
+			}
+			continue
 		}
-		// us crash
-		if nodes := htmlquery.Find(srcNode, tableUSCrashXpath); nodes != nil {
-			snapshot.USCrashes.Fatal = parseInt(strings.TrimSpace(nodes[0].Data))
-			snapshot.USCrashes.Injury = parseInt(strings.TrimSpace(nodes[1].Data))
-			snapshot.USCrashes.Tow = parseInt(strings.TrimSpace(nodes[2].Data))
-			snapshot.USCrashes.Total = parseInt(strings.TrimSpace(nodes[3].Data))
+		if section.labeled {
+			p.parseLabeledSection(snapshot, section, node)
+		} else {
+			p.parseSection(snapshot, section, node)
 		}
-		// canada inspection
-		if nodes := htmlquery.Find(srcNode, tableCanadaInspectionXpath); nodes != nil {
-			snapshot.CanadaVehicleInspections.Inspections = parseInt(getNodeText(nodes[1], "/td[1]/text()"))
-			snapshot.CanadaDriverInspections.Inspections = parseInt(getNodeText(nodes[1], "/td[2]/text()"))
-			snapshot.CanadaVehicleInspections.OutOfService = parseInt(getNodeText(nodes[2], "/td[1]/text()"))
-			snapshot.CanadaDriverInspections.OutOfService = parseInt(getNodeText(nodes[2], "/td[2]/text()"))
-			snapshot.CanadaVehicleInspections.OutOfServicePct = parsePctToFloat32(getNodeText(nodes[3], "/td[1]/text()"))
-			snapshot.CanadaDriverInspections.OutOfServicePct = parsePctToFloat32(getNodeText(nodes[3], "/td[2]/text()"))
+	}
+	return snapshot, nil
+}
 
-		}
-		// canada crash
-		if nodes := htmlquery.Find(srcNode, tableCanadaCrashXpath); nodes != nil {
-			snapshot.CanadaCrashes.Fatal = parseInt(strings.TrimSpace(nodes[0].Data))
-			snapshot.CanadaCrashes.Injury = parseInt(strings.TrimSpace(nodes[1].Data))
-			snapshot.CanadaCrashes.Tow = parseInt(strings.TrimSpace(nodes[2].Data))
-			snapshot.CanadaCrashes.Total = parseInt(strings.TrimSpace(nodes[3].Data))
-		}
-		// safety rating
-		if nodes := htmlquery.Find(srcNode, tableSafetyRatingXpath); nodes != nil {
-			if tr2 := htmlquery.Find(nodes[1], "/td/text()"); tr2 != nil {
-				snapshot.Safety.RatingDate = parseDate(strings.TrimSpace(tr2[0].Data))
-				snapshot.Safety.ReviewDate = parseDate(strings.TrimSpace(tr2[1].Data))
+// rowsSelector selects the rows of a section table
+var rowsSelector = mustCompileSelector("/tr")
+
+// parseSection sets the fields of a section found by path. Fields in rows or cells missing from the section are
+// left at their zero value with a warning.
+func (p *snapshotParser) parseSection(snapshot *CompanySnapshot, section *compiledSection, node *html.Node) {
+	var rows []*html.Node
+	var missingRows map[int]bool
+	for i := range section.fields {
+		f := &section.fields[i]
+		target := node
+		if f.row > 0 {
+			if rows == nil {
+				rows = rowsSelector.find(node)
 			}
-			if tr3 := htmlquery.Find(nodes[2], "/td/text()"); tr3 != nil {
-				snapshot.Safety.Rating = strings.TrimSpace(tr3[0].Data)
-				snapshot.Safety.Type = strings.TrimSpace(tr3[1].Data)
+			if f.row > len(rows) {
+				if !missingRows[f.row] {
+					if missingRows == nil {
+						missingRows = make(map[int]bool)
+					}
+					missingRows[f.row] = true
+					p.warn(section.name, "row %d missing", f.row)
+				}
+				f.set(snapshot, f.parse(p, f.name, nil))
+				continue
 			}
+			target = rows[f.row-1]
+		}
+		nodes := f.path.find(target)
+		if len(nodes) == 0 && f.row > 0 {
+			p.warn(section.name, "no value for %s", f.name)
 		}
+		f.set(snapshot, f.parse(p, f.name, nodes))
 	}
-	return snapshot, nil
+}
+
+// checkedStatus is the status of a checkbox grid with n boxes checked
+func checkedStatus(n int) FieldStatus {
+	if n == 0 {
+		return FieldEmpty
+	}
+	return FieldFound
 }
 
 func htmlNodeToCompanyResults(node *html.Node) ([]CompanyResult, error) {
 	resultNodes := htmlquery.Find(node, companyResultXpath)
 	if resultNodes == nil || len(resultNodes) == 0 {
+		if err := classifyPage(node); err != nil {
+			return nil, err
+		}
 		return []CompanyResult{}, nil
 	}
 	companyResults := make([]CompanyResult, len(resultNodes), len(resultNodes))
@@ -184,6 +180,9 @@ func companyResultFromNode(n *html.Node) CompanyResult {
 }
 
 func getNodeText(node *html.Node, path string) string {
+	if node == nil {
+		return ""
+	}
 	child := htmlquery.FindOne(node, path)
 	if child == nil {
 		return ""
@@ -192,6 +191,9 @@ func getNodeText(node *html.Node, path string) string {
 }
 
 func getNodeTexts(node *html.Node, path string) []string {
+	if node == nil {
+		return []string{}
+	}
 	children := htmlquery.Find(node, path)
 	if children == nil || len(children) == 0 {
 		return []string{}
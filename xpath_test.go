@@ -0,0 +1,201 @@
+package safer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func TestParseCompanySnapshot(t *testing.T) {
+	data, err := os.ReadFile("./testdata/snapshot-basic.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := ParseCompanySnapshot(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseCompanySnapshot should return no error, but got %v", err)
+	}
+	if snapshot.DOTNumber != "264184" || snapshot.LegalName != "SCHNEIDER NATIONAL CARRIERS INC" {
+		t.Errorf("ParseCompanySnapshot() = %v, want SCHNEIDER NATIONAL CARRIERS INC (264184)", snapshot)
+	}
+
+	node, err := htmlquery.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromNode, err := ParseCompanySnapshotNode(node)
+	if err != nil {
+		t.Fatalf("ParseCompanySnapshotNode should return no error, but got %v", err)
+	}
+	if !reflect.DeepEqual(snapshot, fromNode) {
+		t.Errorf("ParseCompanySnapshotNode() = %v, want %v", fromNode, snapshot)
+	}
+}
+
+func TestParseCompanySnapshot_NotFound(t *testing.T) {
+	f, err := os.Open("./testdata/not-found.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	snapshot, err := ParseCompanySnapshot(f)
+	if err != ErrCompanyNotFound {
+		t.Errorf("ParseCompanySnapshot should return ErrCompanyNotFound but got %v", err)
+	}
+	if snapshot != nil {
+		t.Errorf("snapshot should return nil")
+	}
+}
+
+func TestParseCompanySnapshot_ReadError(t *testing.T) {
+	if _, err := ParseCompanySnapshot(errReader{}); !errors.Is(err, ErrParse) {
+		t.Errorf("ParseCompanySnapshot should return ErrParse, but got %v", err)
+	}
+	if _, _, err := ParseCompanySnapshotWithReport(errReader{}, ParseOptions{}); !errors.Is(err, ErrParse) {
+		t.Errorf("ParseCompanySnapshotWithReport should return ErrParse, but got %v", err)
+	}
+}
+
+func TestParseSearchResults(t *testing.T) {
+	f, err := os.Open("./testdata/search-result-short.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	results, err := ParseSearchResults(f)
+	if err != nil {
+		t.Fatalf("ParseSearchResults should return no error, but got %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("results length = %v, want %v", len(results), 4)
+	}
+
+	node, err := htmlquery.Parse(strings.NewReader("<html><body></body></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err = ParseSearchResultsNode(node)
+	if err != nil || len(results) != 0 {
+		t.Errorf("ParseSearchResultsNode() = %v, %v, want empty results", results, err)
+	}
+}
+
+func TestParseCompanySnapshotWithReport_Mangled(t *testing.T) {
+	tests := []struct {
+		file         string
+		wantWarnings []ParseWarning
+		check        func(*CompanySnapshot) bool
+	}{
+		{
+			file: "inspections-short.html",
+			wantWarnings: []ParseWarning{
+				{Section: SectionUSInspections, Message: "row 3 missing"},
+				{Section: SectionUSInspections, Message: "row 4 missing"},
+				{Section: SectionUSInspections, Message: "row 5 missing"},
+			},
+			check: func(s *CompanySnapshot) bool {
+				return s.USVehicleInspections.Inspections == 7276 && s.USVehicleInspections.OutOfService == 0 && s.USCrashes.Total == 837
+			},
+		},
+		{
+			file: "crashes-short.html",
+			wantWarnings: []ParseWarning{
+				{Section: SectionUSCrashes, Message: "no value for us_crashes.tow"},
+				{Section: SectionUSCrashes, Message: "no value for us_crashes.total"},
+			},
+			check: func(s *CompanySnapshot) bool {
+				return s.USCrashes.Injury == 248 && s.USCrashes.Tow == 0 && s.USCrashes.Total == 0
+			},
+		},
+		{
+			file: "safety-rating-short.html",
+			wantWarnings: []ParseWarning{
+				{Section: SectionSafetyRating, Message: "no value for safety.review_date"},
+				{Section: SectionSafetyRating, Message: "row 3 missing"},
+			},
+			check: func(s *CompanySnapshot) bool {
+				return s.Safety.RatingDate != nil && s.Safety.ReviewDate == nil && s.Safety.Rating == ""
+			},
+		},
+		{
+			file: "truncated.html",
+			wantWarnings: []ParseWarning{
+				{Section: SectionUSInspections, Message: "row 3 missing"},
+				{Section: SectionUSInspections, Message: "row 4 missing"},
+				{Section: SectionUSInspections, Message: "row 5 missing"},
+				{Section: SectionUSInspections, Message: "no value for us_driver_inspections.inspections"},
+				{Section: SectionUSInspections, Message: "no value for us_hazmat_inspections.inspections"},
+				{Section: SectionUSInspections, Message: "no value for us_iep_inspections.inspections"},
+			},
+			check: func(s *CompanySnapshot) bool {
+				return s.LegalName == "SCHNEIDER NATIONAL CARRIERS INC" && s.USVehicleInspections.Inspections == 7276
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data := readTestData("./testdata/mangled/" + tt.file)
+			snapshot, report, err := ParseCompanySnapshotWithReport(bytes.NewReader(data), ParseOptions{})
+			if err != nil {
+				t.Fatalf("ParseCompanySnapshotWithReport should return no error, but got %v", err)
+			}
+			if !reflect.DeepEqual(report.Warnings, tt.wantWarnings) {
+				t.Errorf("Warnings = %v, want %v", report.Warnings, tt.wantWarnings)
+			}
+			if !tt.check(snapshot) {
+				t.Errorf("ParseCompanySnapshotWithReport() = %v, missing the partial data", snapshot)
+			}
+		})
+	}
+}
+
+// mangledSnapshots returns variants of the snapshot fixtures that have been truncated at regular offsets or had a
+// single table row removed
+func mangledSnapshots(t *testing.T) map[string][]byte {
+	files, err := filepath.Glob("./testdata/snapshot-*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mangledFiles, err := filepath.Glob("./testdata/mangled/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, mangledFiles...)
+
+	rowRegex := regexp.MustCompile(`(?is)<tr[ >].*?</tr>`)
+	variants := make(map[string][]byte)
+	for _, file := range files {
+		data := readTestData(file)
+		variants[file] = data
+		for offset := 0; offset < len(data); offset += 997 {
+			variants[fmt.Sprintf("%s[:%d]", file, offset)] = data[:offset]
+		}
+		for i, loc := range rowRegex.FindAllIndex(data, -1) {
+			mangled := append(append([]byte{}, data[:loc[0]]...), data[loc[1]:]...)
+			variants[fmt.Sprintf("%s without row %d", file, i+1)] = mangled
+		}
+	}
+	return variants
+}
+
+func TestParseCompanySnapshot_NeverPanics(t *testing.T) {
+	variants := mangledSnapshots(t)
+	for name, data := range variants {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s: ParseCompanySnapshotWithReport panicked: %v", name, r)
+				}
+			}()
+			ParseCompanySnapshotWithReport(bytes.NewReader(data), ParseOptions{})
+		}()
+	}
+}